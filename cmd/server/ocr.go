@@ -1,13 +1,24 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
+	"os"
 
+	"app/internal/config"
 	"app/internal/server"
 )
 
 func main() {
-	if err := server.Run(); err != nil {
+	cfg, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := server.Run(cfg); err != nil {
 		log.Fatal(err)
 	}
 }
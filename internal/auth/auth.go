@@ -0,0 +1,173 @@
+// Package auth authenticates API clients by their keys, which are kept as SHA-256 hashes
+// and carry scopes and an optional expiry.
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidKey is returned for keys that match no configured key.
+	ErrInvalidKey = errors.New("invalid api key")
+	// ErrExpiredKey is returned for keys past their expiry.
+	ErrExpiredKey = errors.New("api key expired")
+)
+
+// Scope grants access to a group of routes.
+type Scope string
+
+// Scopes a key may hold. ScopeAdmin grants every other scope.
+const (
+	ScopeOCRRead   Scope = "ocr:read"   // Synchronous OCR, languages, field extraction and reading jobs
+	ScopeJobsWrite Scope = "jobs:write" // Submitting, reading and cancelling jobs
+	ScopeAdmin     Scope = "admin"      // Everything, including the Ollama proxy
+)
+
+// Scopes lists every valid scope.
+var Scopes = []Scope{ScopeOCRRead, ScopeJobsWrite, ScopeAdmin}
+
+// hashPrefix marks the hash algorithm in key files.
+const hashPrefix = "sha256:"
+
+var keyName = regexp.MustCompile(`^[A-Za-z0-9._@-]{1,64}$`)
+
+// Key is a client's API key, identified by name.
+type Key struct {
+	Name    string
+	Hash    [sha256.Size]byte // SHA-256 of the secret
+	Scopes  []Scope
+	Expires time.Time // Zero for keys that do not expire
+}
+
+// Allows reports whether the key grants scope.
+func (k Key) Allows(scope Scope) bool {
+	return scope == "" || slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+// Expired reports whether the key has expired at now.
+func (k Key) Expired(now time.Time) bool {
+	return !k.Expires.IsZero() && !now.Before(k.Expires)
+}
+
+// Hash returns the hash of secret as written in key files, "sha256:" and hex digits.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// ParseHash parses a hash written by Hash. The "sha256:" prefix is optional.
+func ParseHash(s string) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), hashPrefix))
+	if err != nil || len(b) != sha256.Size {
+		return hash, errors.New("hash must be sha256: followed by 64 hex digits")
+	}
+	copy(hash[:], b)
+	return hash, nil
+}
+
+// SingleKey returns the key named "default" with every scope, used for a plain API_KEY secret.
+func SingleKey(secret string) Key {
+	return Key{Name: "default", Hash: sha256.Sum256([]byte(secret)), Scopes: []Scope{ScopeAdmin}}
+}
+
+// Keyring holds the keys clients authenticate with. It is safe for concurrent use, and
+// Replace swaps its keys without interrupting requests.
+type Keyring struct {
+	mu   sync.RWMutex
+	keys []Key
+	now  func() time.Time
+}
+
+// NewKeyring validates keys and returns a keyring holding them.
+func NewKeyring(keys []Key) (*Keyring, error) {
+	k := &Keyring{now: time.Now}
+	if err := k.Replace(keys); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Replace validates keys and swaps them in for the current ones. On error the current
+// keys are kept.
+func (k *Keyring) Replace(keys []Key) error {
+	if err := validate(keys); err != nil {
+		return err
+	}
+	keys = slices.Clone(keys)
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of keys, including expired ones.
+func (k *Keyring) Len() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.keys)
+}
+
+// Authenticate returns the key matching secret. The secret's hash is compared with every
+// key in constant time, so response times do not reveal how close a guess was.
+func (k *Keyring) Authenticate(secret string) (Key, error) {
+	if secret == "" {
+		return Key{}, ErrInvalidKey
+	}
+	hash := sha256.Sum256([]byte(secret))
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	match := -1
+	for i := range k.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.keys[i].Hash[:]) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return Key{}, ErrInvalidKey
+	}
+	key := k.keys[match]
+	if key.Expired(k.now()) {
+		return Key{}, fmt.Errorf("%w: %s", ErrExpiredKey, key.Name)
+	}
+	return key, nil
+}
+
+// validate checks names are unique and valid, hashes are distinct and scopes are known.
+func validate(keys []Key) error {
+	var errs []error
+	names := make(map[string]bool, len(keys))
+	hashes := make(map[[sha256.Size]byte]bool, len(keys))
+	for i, key := range keys {
+		switch {
+		case !keyName.MatchString(key.Name):
+			errs = append(errs, fmt.Errorf("key %d: name must be 1-64 letters, digits, '.', '_', '@' or '-', got %q", i+1, key.Name))
+		case names[key.Name]:
+			errs = append(errs, fmt.Errorf("key %s: duplicate name", key.Name))
+		}
+		names[key.Name] = true
+		if hashes[key.Hash] {
+			errs = append(errs, fmt.Errorf("key %s: same secret as another key", key.Name))
+		}
+		hashes[key.Hash] = true
+		if len(key.Scopes) == 0 {
+			errs = append(errs, fmt.Errorf("key %s: no scopes", key.Name))
+		}
+		for _, scope := range key.Scopes {
+			if !slices.Contains(Scopes, scope) {
+				errs = append(errs, fmt.Errorf("key %s: unknown scope %q", key.Name, scope))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustHash(t *testing.T, secret string) [32]byte {
+	t.Helper()
+	hash, err := ParseHash(Hash(secret))
+	if err != nil {
+		t.Fatalf("parse hash: %v", err)
+	}
+	return hash
+}
+
+func TestKeyring_Authenticate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	keyring, err := NewKeyring([]Key{
+		{Name: "billing", Hash: mustHash(t, "billing-secret"), Scopes: []Scope{ScopeOCRRead}},
+		{Name: "old", Hash: mustHash(t, "old-secret"), Scopes: []Scope{ScopeOCRRead}, Expires: now.Add(-time.Hour)},
+		SingleKey("root-secret"),
+	})
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	keyring.now = func() time.Time { return now }
+
+	key, err := keyring.Authenticate("billing-secret")
+	if err != nil || key.Name != "billing" {
+		t.Fatalf("expected billing key, got %+v %v", key, err)
+	}
+	if !key.Allows(ScopeOCRRead) || key.Allows(ScopeJobsWrite) || key.Allows(ScopeAdmin) {
+		t.Fatalf("unexpected scopes: %v", key.Scopes)
+	}
+
+	key, err = keyring.Authenticate("root-secret")
+	if err != nil || !key.Allows(ScopeJobsWrite) || !key.Allows(ScopeAdmin) {
+		t.Fatalf("expected the default key to allow everything, got %+v %v", key, err)
+	}
+
+	if _, err := keyring.Authenticate("old-secret"); !errors.Is(err, ErrExpiredKey) {
+		t.Fatalf("expected expired key, got %v", err)
+	}
+	for _, secret := range []string{"", "wrong", "billing-secret "} {
+		if _, err := keyring.Authenticate(secret); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("%q: expected invalid key, got %v", secret, err)
+		}
+	}
+}
+
+func TestKeyring_Replace(t *testing.T) {
+	keyring, err := NewKeyring([]Key{SingleKey("first")})
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+
+	bad := []Key{{Name: "x", Hash: mustHash(t, "second"), Scopes: []Scope{"ocr:write"}}}
+	if err := keyring.Replace(bad); err == nil {
+		t.Fatal("expected unknown scope to be rejected")
+	}
+	if _, err := keyring.Authenticate("first"); err != nil {
+		t.Fatalf("expected failed replace to keep keys, got %v", err)
+	}
+
+	if err := keyring.Replace([]Key{{Name: "x", Hash: mustHash(t, "second"), Scopes: []Scope{ScopeOCRRead}}}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if _, err := keyring.Authenticate("first"); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected replaced key to be gone, got %v", err)
+	}
+	if _, err := keyring.Authenticate("second"); err != nil {
+		t.Fatalf("expected new key, got %v", err)
+	}
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []Key
+		expected string
+	}{
+		{"bad name", []Key{{Name: "a b", Scopes: []Scope{ScopeAdmin}}}, "name must be"},
+		{"duplicate name", []Key{SingleKey("a"), SingleKey("b")}, "duplicate name"},
+		{"shared secret", []Key{{Name: "a", Hash: mustHash(t, "s"), Scopes: []Scope{ScopeAdmin}}, {Name: "b", Hash: mustHash(t, "s"), Scopes: []Scope{ScopeAdmin}}}, "same secret"},
+		{"no scopes", []Key{{Name: "a"}}, "no scopes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.keys)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func writeKeys(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeKeys(t, `keys:
+  - name: billing
+    hash: `+Hash("billing-secret")+`
+    scopes: [ocr:read, jobs:write]
+    expires: 2027-01-01
+  - name: ops
+    hash: `+strings.TrimPrefix(Hash("ops-secret"), "sha256:")+`
+    scopes: [admin]
+    expires: "2026-12-31T18:00:00+07:00"
+  - name: ci
+    hash: `+Hash("ci-secret")+`
+    scopes: [ocr:read]
+`)
+
+	keys, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d", len(keys))
+	}
+	if keys[0].Name != "billing" || keys[0].Hash != mustHash(t, "billing-secret") || len(keys[0].Scopes) != 2 {
+		t.Fatalf("unexpected billing key: %+v", keys[0])
+	}
+	if !keys[0].Expires.Equal(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected billing expiry: %v", keys[0].Expires)
+	}
+	if !keys[1].Expires.Equal(time.Date(2026, 12, 31, 11, 0, 0, 0, time.UTC)) || keys[1].Hash != mustHash(t, "ops-secret") {
+		t.Fatalf("unexpected ops key: %+v", keys[1])
+	}
+	if !keys[2].Expires.IsZero() {
+		t.Fatalf("expected ci key not to expire, got %v", keys[2].Expires)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"unknown field", "keys:\n  - name: a\n    secret: plain\n", "secret"},
+		{"bad hash", "keys:\n  - name: a\n    hash: plain\n    scopes: [admin]\n", "64 hex digits"},
+		{"bad expiry", "keys:\n  - name: a\n    hash: " + Hash("a") + "\n    scopes: [admin]\n    expires: soon\n", "expires"},
+		{"unknown scope", "keys:\n  - name: a\n    hash: " + Hash("a") + "\n    scopes: [root]\n", "unknown scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFile(writeKeys(t, tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("expected missing file to fail")
+	}
+}
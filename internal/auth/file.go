@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// fileKey is the form of a Key in a key file.
+type fileKey struct {
+	Name    string   `yaml:"name"`
+	Hash    string   `yaml:"hash"`    // Written by Hash; secrets are never stored
+	Scopes  []string `yaml:"scopes"`  // Scope values
+	Expires string   `yaml:"expires"` // RFC 3339 time or YYYY-MM-DD (UTC midnight); empty never expires
+}
+
+// LoadFile reads keys from a YAML or JSON file of the form
+//
+//	keys:
+//	  - name: billing
+//	    hash: sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//	    scopes: [ocr:read, jobs:write]
+//	    expires: 2027-01-01
+//
+// Unknown fields are rejected so that typos do not silently drop restrictions.
+func LoadFile(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read api keys: %w", err)
+	}
+	var doc struct {
+		Keys []fileKey `yaml:"keys"`
+	}
+	if err := yaml.UnmarshalWithOptions(data, &doc, yaml.Strict()); err != nil {
+		return nil, fmt.Errorf("parse api keys %s: %w", path, err)
+	}
+
+	keys := make([]Key, 0, len(doc.Keys))
+	var errs []error
+	for i, fk := range doc.Keys {
+		key, err := fk.key()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %d (%s): %w", i+1, fk.Name, err))
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("parse api keys %s: %w", path, err)
+	}
+	if err := validate(keys); err != nil {
+		return nil, fmt.Errorf("parse api keys %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+func (fk fileKey) key() (Key, error) {
+	hash, err := ParseHash(fk.Hash)
+	if err != nil {
+		return Key{}, err
+	}
+	key := Key{Name: fk.Name, Hash: hash}
+	for _, scope := range fk.Scopes {
+		key.Scopes = append(key.Scopes, Scope(scope))
+	}
+	if expires := strings.TrimSpace(fk.Expires); expires != "" {
+		if key.Expires, err = parseExpiry(expires); err != nil {
+			return Key{}, err
+		}
+	}
+	return key, nil
+}
+
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expires must be an RFC 3339 time or YYYY-MM-DD, got %q", s)
+}
@@ -0,0 +1,230 @@
+// Package config loads the server configuration from defaults, an optional YAML or
+// TOML file, environment variables and command-line flags, in that order of precedence.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"app/internal/ocr"
+)
+
+// Config is the complete server configuration.
+type Config struct {
+	Server     ServerConfig     `yaml:"server" toml:"server"`
+	OCR        OCRConfig        `yaml:"ocr" toml:"ocr"`
+	Jobs       JobsConfig       `yaml:"jobs" toml:"jobs"`
+	Cache      CacheConfig      `yaml:"cache" toml:"cache"`
+	Ollama     OllamaConfig     `yaml:"ollama" toml:"ollama"`
+	Correction CorrectionConfig `yaml:"correction" toml:"correction"`
+	Extraction ExtractionConfig `yaml:"extraction" toml:"extraction"`
+}
+
+// ServerConfig controls the HTTP server.
+type ServerConfig struct {
+	Port              int      `yaml:"port" toml:"port"`
+	APIKey            string   `yaml:"api_key" toml:"api_key"`             // Single key with every scope, named "default"
+	APIKeysFile       string   `yaml:"api_keys_file" toml:"api_keys_file"` // Named, hashed and scoped keys, reloaded on SIGHUP
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`   // Allow large file uploads
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"` // Allow long OCR processing
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	DrainTimeout      Duration `yaml:"drain_timeout" toml:"drain_timeout"`             // How long in-flight work may finish on shutdown
+	MultipartMemoryMB int64    `yaml:"multipart_memory_mb" toml:"multipart_memory_mb"` // Upload bytes kept in memory; the rest spills to temp files
+	MinFreeDiskMB     int64    `yaml:"min_free_disk_mb" toml:"min_free_disk_mb"`       // Free temp dir space /readyz requires
+}
+
+// OCRConfig controls the OCR pipeline.
+type OCRConfig struct {
+	Engine          string   `yaml:"engine" toml:"engine"`                     // ocrmypdf or tesseract
+	Timeout         Duration `yaml:"timeout" toml:"timeout"`                   // Per OCR engine run
+	DefaultLanguage string   `yaml:"default_language" toml:"default_language"` // Used when a request has no lang
+	TextThreshold   int      `yaml:"text_threshold" toml:"text_threshold"`     // Text layer characters needed to skip OCR
+	MaxPages        int      `yaml:"max_pages" toml:"max_pages"`               // Per document, 0 for no limit
+	Concurrency     int      `yaml:"concurrency" toml:"concurrency"`           // Pages of a document processed in parallel, 0 for one per CPU
+	PageLimit       int      `yaml:"page_limit" toml:"page_limit"`             // Pages processed at once across all requests and jobs, 0 for one per CPU
+}
+
+// JobsConfig controls the asynchronous job queue.
+type JobsConfig struct {
+	StoreDir string `yaml:"store_dir" toml:"store_dir"` // Persist jobs on disk; empty keeps them in memory
+	Workers  int    `yaml:"workers" toml:"workers"`
+	// Comma-separated CIDRs callbacks may reach besides public addresses, such as 10.0.0.0/8
+	CallbackAllowedNetworks string `yaml:"callback_allowed_networks" toml:"callback_allowed_networks"`
+}
+
+// CallbackNetworks parses CallbackAllowedNetworks.
+func (c JobsConfig) CallbackNetworks() ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	for _, cidr := range strings.Split(c.CallbackAllowedNetworks, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// CacheConfig controls the OCR result cache.
+type CacheConfig struct {
+	Disabled   bool     `yaml:"disabled" toml:"disabled"`
+	Dir        string   `yaml:"dir" toml:"dir"` // Cache on disk; empty keeps it in memory
+	TTL        Duration `yaml:"ttl" toml:"ttl"`
+	MaxEntries int      `yaml:"max_entries" toml:"max_entries"` // In memory
+	MaxMB      int      `yaml:"max_mb" toml:"max_mb"`           // On disk
+}
+
+// OllamaConfig controls the Ollama server behind the authenticated proxy and LLM features.
+type OllamaConfig struct {
+	URL         string   `yaml:"url" toml:"url"`                   // Empty disables the proxy and LLM features
+	Model       string   `yaml:"model" toml:"model"`               // Model used by LLM features
+	Prefix      string   `yaml:"prefix" toml:"prefix"`             // Mount path, stripped before forwarding
+	Timeout     Duration `yaml:"timeout" toml:"timeout"`           // Waiting for response headers
+	DialTimeout Duration `yaml:"dial_timeout" toml:"dial_timeout"` // Connecting to the server
+}
+
+// CorrectionConfig controls LLM post-correction of OCRed pages, requested with correct=true.
+type CorrectionConfig struct {
+	Enabled       bool     `yaml:"enabled" toml:"enabled"`               // Requires ollama.url and ollama.model
+	MinConfidence float64  `yaml:"min_confidence" toml:"min_confidence"` // Layout pages recognized at least this confidently are skipped
+	Timeout       Duration `yaml:"timeout" toml:"timeout"`               // Per page
+}
+
+// ExtractionConfig controls structured field extraction, which uses ollama.model when set
+// and rule-based extractors otherwise.
+type ExtractionConfig struct {
+	Timeout Duration `yaml:"timeout" toml:"timeout"` // Per LLM request
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			ReadTimeout:       Duration(120 * time.Minute),
+			WriteTimeout:      Duration(120 * time.Minute),
+			IdleTimeout:       Duration(120 * time.Second),
+			DrainTimeout:      Duration(30 * time.Second),
+			MultipartMemoryMB: 100,
+			MinFreeDiskMB:     512,
+		},
+		OCR: OCRConfig{
+			Engine:          ocr.EngineOCRmyPDF,
+			Timeout:         Duration(2 * time.Minute),
+			DefaultLanguage: "eng+chi_sim+ind",
+			TextThreshold:   150,
+		},
+		Cache: CacheConfig{
+			TTL:        Duration(24 * time.Hour),
+			MaxEntries: 256,
+			MaxMB:      1024,
+		},
+		Ollama: OllamaConfig{
+			Prefix:      "/ollama",
+			Timeout:     Duration(5 * time.Minute),
+			DialTimeout: Duration(10 * time.Second),
+		},
+		Correction: CorrectionConfig{
+			MinConfidence: 90,
+			Timeout:       Duration(2 * time.Minute),
+		},
+		Extraction: ExtractionConfig{
+			Timeout: Duration(5 * time.Minute),
+		},
+	}
+}
+
+var prefixPattern = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)+$`)
+
+// reservedPrefixes are the first path segments of the service's own routes.
+var reservedPrefixes = []string{"/api", "/healthz", "/readyz", "/metrics"}
+
+// Validate reports every invalid setting, naming each by its file key.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout", "must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout", "must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout", "must be positive")
+	check(c.Server.DrainTimeout >= 0, "server.drain_timeout", "must not be negative")
+	check(c.Server.MultipartMemoryMB > 0, "server.multipart_memory_mb", "must be positive")
+	check(c.Server.MinFreeDiskMB >= 0, "server.min_free_disk_mb", "must not be negative")
+
+	check(c.OCR.Engine == ocr.EngineOCRmyPDF || c.OCR.Engine == ocr.EngineTesseract, "ocr.engine", "must be %q or %q, got %q", ocr.EngineOCRmyPDF, ocr.EngineTesseract, c.OCR.Engine)
+	check(c.OCR.Timeout > 0, "ocr.timeout", "must be positive")
+	check(ocr.LanguagePattern.MatchString(c.OCR.DefaultLanguage), "ocr.default_language", "must be language codes joined by +, got %q", c.OCR.DefaultLanguage)
+	check(c.OCR.TextThreshold > 0, "ocr.text_threshold", "must be positive")
+	check(c.OCR.MaxPages >= 0, "ocr.max_pages", "must not be negative")
+	check(c.OCR.Concurrency >= 0, "ocr.concurrency", "must not be negative")
+	check(c.OCR.PageLimit >= 0, "ocr.page_limit", "must not be negative")
+
+	check(c.Jobs.Workers >= 0, "jobs.workers", "must not be negative")
+	_, err := c.Jobs.CallbackNetworks()
+	check(err == nil, "jobs.callback_allowed_networks", "must be CIDRs joined by commas: %v", err)
+
+	if !c.Cache.Disabled {
+		check(c.Cache.TTL > 0, "cache.ttl", "must be positive")
+		check(c.Cache.MaxEntries >= 0, "cache.max_entries", "must not be negative")
+		check(c.Cache.MaxMB > 0, "cache.max_mb", "must be positive")
+	}
+
+	if c.Ollama.URL != "" {
+		u, err := url.Parse(c.Ollama.URL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "ollama.url", "must be an absolute http(s) URL, got %q", c.Ollama.URL)
+		check(prefixPattern.MatchString(c.Ollama.Prefix), "ollama.prefix", "must be a path such as /ollama, got %q", c.Ollama.Prefix)
+		check(!slices.ContainsFunc(reservedPrefixes, func(reserved string) bool {
+			return c.Ollama.Prefix == reserved || strings.HasPrefix(c.Ollama.Prefix, reserved+"/")
+		}), "ollama.prefix", "must not overlap the service routes, got %q", c.Ollama.Prefix)
+		check(c.Ollama.Timeout > 0, "ollama.timeout", "must be positive")
+		check(c.Ollama.DialTimeout > 0, "ollama.dial_timeout", "must be positive")
+	}
+
+	if c.Correction.Enabled {
+		check(c.Ollama.URL != "", "correction.enabled", "requires ollama.url")
+		check(c.Ollama.Model != "", "correction.enabled", "requires ollama.model")
+		check(c.Correction.MinConfidence > 0 && c.Correction.MinConfidence <= 100, "correction.min_confidence", "must be between 0 and 100, got %g", c.Correction.MinConfidence)
+		check(c.Correction.Timeout > 0, "correction.timeout", "must be positive")
+	}
+
+	check(c.Extraction.Timeout > 0, "extraction.timeout", "must be positive")
+
+	return errors.Join(errs...)
+}
+
+// Duration is a time.Duration read from strings such as "90s" or "2m".
+type Duration time.Duration
+
+// UnmarshalText parses a duration string.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText formats the duration as a string.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Std returns the duration as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
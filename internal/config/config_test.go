@@ -0,0 +1,202 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"app/internal/ocr"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load(nil, env(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != 8080 || cfg.OCR.Timeout.Std() != 2*time.Minute || cfg.OCR.TextThreshold != 150 ||
+		cfg.OCR.DefaultLanguage != "eng+chi_sim+ind" || cfg.Server.MultipartMemoryMB != 100 ||
+		cfg.Server.WriteTimeout.Std() != 120*time.Minute || cfg.Ollama.URL != "" || cfg.Ollama.Prefix != "/ollama" {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: 9090
+  drain_timeout: 1m
+ocr:
+  engine: tesseract
+  timeout: 90s
+  default_language: eng
+cache:
+  disabled: true
+`)
+
+	cfg, err := Load([]string{"-config", path}, env(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != 9090 || cfg.Server.DrainTimeout.Std() != time.Minute || cfg.OCR.Engine != ocr.EngineTesseract ||
+		cfg.OCR.Timeout.Std() != 90*time.Second || cfg.OCR.DefaultLanguage != "eng" || !cfg.Cache.Disabled {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	// Keys missing from the file keep their defaults
+	if cfg.OCR.TextThreshold != 150 {
+		t.Fatalf("expected default text threshold, got %d", cfg.OCR.TextThreshold)
+	}
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[ocr]
+text_threshold = 80
+max_pages = 50
+
+[jobs]
+store_dir = "/var/lib/ocr"
+workers = 4
+`)
+
+	cfg, err := Load(nil, env(map[string]string{FileEnv: path}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OCR.TextThreshold != 80 || cfg.OCR.MaxPages != 50 || cfg.Jobs.StoreDir != "/var/lib/ocr" || cfg.Jobs.Workers != 4 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_BoolFlags(t *testing.T) {
+	cfg, err := Load([]string{"-cache-disabled", "-ollama-url", "http://localhost:11434", "-ollama-model", "llama3", "-correction"}, env(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Cache.Disabled || !cfg.Correction.Enabled {
+		t.Fatalf("expected bare bool flags to mean true, got %+v", cfg)
+	}
+
+	cfg, err = Load([]string{"-cache-disabled=false"}, env(map[string]string{"CACHE_DISABLED": "true"}))
+	if err != nil || cfg.Cache.Disabled {
+		t.Fatalf("expected -cache-disabled=false to win, got %+v %v", cfg, err)
+	}
+}
+
+func TestLoad_EmptyEnvironment(t *testing.T) {
+	path := writeFile(t, "config.yaml", "ollama:\n  url: http://localhost:11434\nocr:\n  max_pages: 10\n")
+
+	cfg, err := Load([]string{"-config", path}, env(map[string]string{"OLLAMA_URL": "", "MAX_PAGES": ""}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Empty variables clear string settings and leave the others alone
+	if cfg.Ollama.URL != "" || cfg.OCR.MaxPages != 10 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yml", "server:\n  port: 9000\nocr:\n  max_pages: 10\n  concurrency: 2\n")
+
+	cfg, err := Load(
+		[]string{"-config", path, "-port", "9002"},
+		env(map[string]string{"PORT": "9001", "MAX_PAGES": "20", "API_KEY": "secret"}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Flags beat the environment, which beats the file
+	if cfg.Server.Port != 9002 || cfg.OCR.MaxPages != 20 || cfg.OCR.Concurrency != 2 || cfg.Server.APIKey != "secret" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		expected string
+	}{
+		{"unknown yaml key", []string{"-config", writeFile(t, "bad.yaml", "ocr:\n  engin: tesseract\n")}, nil, "engin"},
+		{"unknown toml key", []string{"-config", writeFile(t, "bad.toml", "[ocr]\nengin = \"tesseract\"\n")}, nil, "engin"},
+		{"unsupported format", []string{"-config", writeFile(t, "config.json", "{}")}, nil, "unsupported format"},
+		{"missing file", []string{"-config", "/nonexistent/config.yaml"}, nil, "read config"},
+		{"bad env value", nil, map[string]string{"JOB_WORKERS": "many"}, "invalid JOB_WORKERS"},
+		{"bad duration", nil, map[string]string{"OCR_TIMEOUT": "soon"}, "invalid OCR_TIMEOUT"},
+		{"bad flag value", []string{"-max-pages", "x"}, nil, "invalid -max-pages"},
+		{"unknown flag", []string{"-nope"}, nil, "nope"},
+		{"unknown engine", nil, map[string]string{"OCR_ENGINE": "abbyy"}, "ocr.engine"},
+		{"bad language", []string{"-lang", "eng,ind"}, nil, "ocr.default_language"},
+		{"bad ollama url", nil, map[string]string{"OLLAMA_URL": "localhost:11434"}, "ollama.url"},
+		{"bad ollama prefix", nil, map[string]string{"OLLAMA_URL": "http://localhost:11434", "OLLAMA_PREFIX": "ollama/"}, "ollama.prefix"},
+		{"correction without model", nil, map[string]string{"OLLAMA_URL": "http://localhost:11434", "CORRECTION_ENABLED": "true"}, "ollama.model"},
+		{"bad correction confidence", []string{"-correction-min-confidence", "high"}, nil, "invalid -correction-min-confidence"},
+		{"bad extraction timeout", []string{"-extraction-timeout", "0s"}, nil, "extraction.timeout"},
+		{"reserved ollama prefix", nil, map[string]string{"OLLAMA_URL": "http://localhost:11434", "OLLAMA_PREFIX": "/api/v1/llm"}, "ollama.prefix"},
+		{"bad callback network", nil, map[string]string{"JOB_CALLBACK_ALLOWED_NETWORKS": "10.0.0.0/8, 192.168.1.1"}, "jobs.callback_allowed_networks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.args, env(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestJobsConfig_CallbackNetworks(t *testing.T) {
+	cfg, err := Load([]string{"-job-callback-allowed-networks", " 10.1.0.0/16, fd00::/8,"}, env(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	networks, err := cfg.Jobs.CallbackNetworks()
+	if err != nil || len(networks) != 2 || networks[0].String() != "10.1.0.0/16" || networks[1].String() != "fd00::/8" {
+		t.Fatalf("unexpected networks %v: %v", networks, err)
+	}
+}
+
+func TestValidate_ReportsEveryError(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Port = 0
+	cfg.OCR.Timeout = 0
+	cfg.Cache.TTL = -1
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, key := range []string{"server.port", "ocr.timeout", "cache.ttl"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected %s in %v", key, err)
+		}
+	}
+
+	// Cache settings are not checked when the cache is disabled
+	cfg = Default()
+	cfg.Cache.Disabled = true
+	cfg.Cache.TTL = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
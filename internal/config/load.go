@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// FileEnv names the environment variable holding the config file path, also set with -config.
+const FileEnv = "CONFIG_FILE"
+
+// setting is one value that can be overridden by an environment variable and, when flag
+// is set, a command-line flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	value valueSetter
+}
+
+// valueSetter parses a setting's value into the config.
+type valueSetter struct {
+	set     func(c *Config, value string) error
+	text    bool // An empty environment variable clears the setting instead of being ignored
+	boolean bool // The flag may be given without a value, meaning true
+}
+
+// settings lists every environment and flag override. Secrets such as the API key have
+// no flag so they do not show up in process listings.
+var settings = []setting{
+	{"PORT", "port", "HTTP port", intSetting(func(c *Config) *int { return &c.Server.Port })},
+	{"API_KEY", "", "", stringSetting(func(c *Config) *string { return &c.Server.APIKey })},
+	{"API_KEYS_FILE", "api-keys-file", "YAML or JSON file of named, hashed and scoped API keys", stringSetting(func(c *Config) *string { return &c.Server.APIKeysFile })},
+	{"SERVER_READ_TIMEOUT", "read-timeout", "HTTP read timeout", durationSetting(func(c *Config) *Duration { return &c.Server.ReadTimeout })},
+	{"SERVER_WRITE_TIMEOUT", "write-timeout", "HTTP write timeout", durationSetting(func(c *Config) *Duration { return &c.Server.WriteTimeout })},
+	{"SERVER_IDLE_TIMEOUT", "idle-timeout", "HTTP keep-alive idle timeout", durationSetting(func(c *Config) *Duration { return &c.Server.IdleTimeout })},
+	{"DRAIN_TIMEOUT", "drain-timeout", "how long in-flight work may finish on shutdown", durationSetting(func(c *Config) *Duration { return &c.Server.DrainTimeout })},
+	{"MULTIPART_MEMORY_MB", "multipart-memory-mb", "upload megabytes kept in memory", int64Setting(func(c *Config) *int64 { return &c.Server.MultipartMemoryMB })},
+	{"MIN_FREE_DISK_MB", "min-free-disk-mb", "free temp dir megabytes required by /readyz", int64Setting(func(c *Config) *int64 { return &c.Server.MinFreeDiskMB })},
+
+	{"OCR_ENGINE", "engine", "OCR engine: ocrmypdf or tesseract", stringSetting(func(c *Config) *string { return &c.OCR.Engine })},
+	{"OCR_TIMEOUT", "ocr-timeout", "timeout of a single OCR engine run", durationSetting(func(c *Config) *Duration { return &c.OCR.Timeout })},
+	{"OCR_DEFAULT_LANGUAGE", "lang", "languages used when a request has none", stringSetting(func(c *Config) *string { return &c.OCR.DefaultLanguage })},
+	{"OCR_TEXT_THRESHOLD", "text-threshold", "text layer characters needed to skip OCR", intSetting(func(c *Config) *int { return &c.OCR.TextThreshold })},
+	{"MAX_PAGES", "max-pages", "pages processed per document, 0 for no limit", intSetting(func(c *Config) *int { return &c.OCR.MaxPages })},
+	{"OCR_CONCURRENCY", "concurrency", "pages of a document processed in parallel, 0 for one per CPU", intSetting(func(c *Config) *int { return &c.OCR.Concurrency })},
+	{"OCR_PAGE_LIMIT", "page-limit", "pages processed at once across all requests and jobs, 0 for one per CPU", intSetting(func(c *Config) *int { return &c.OCR.PageLimit })},
+
+	{"JOB_STORE_DIR", "job-store-dir", "directory persisting jobs, empty for memory", stringSetting(func(c *Config) *string { return &c.Jobs.StoreDir })},
+	{"JOB_WORKERS", "job-workers", "jobs processed concurrently", intSetting(func(c *Config) *int { return &c.Jobs.Workers })},
+	{"JOB_CALLBACK_ALLOWED_NETWORKS", "job-callback-allowed-networks", "comma-separated CIDRs job callbacks may reach besides public addresses", stringSetting(func(c *Config) *string { return &c.Jobs.CallbackAllowedNetworks })},
+
+	{"CACHE_DISABLED", "cache-disabled", "turn off the result cache", boolSetting(func(c *Config) *bool { return &c.Cache.Disabled })},
+	{"CACHE_DIR", "cache-dir", "directory caching results, empty for memory", stringSetting(func(c *Config) *string { return &c.Cache.Dir })},
+	{"CACHE_TTL", "cache-ttl", "how long unused results are cached", durationSetting(func(c *Config) *Duration { return &c.Cache.TTL })},
+	{"CACHE_MAX_ENTRIES", "cache-max-entries", "results cached in memory", intSetting(func(c *Config) *int { return &c.Cache.MaxEntries })},
+	{"CACHE_MAX_MB", "cache-max-mb", "megabytes cached on disk", intSetting(func(c *Config) *int { return &c.Cache.MaxMB })},
+
+	{"OLLAMA_URL", "ollama-url", "Ollama server proxied under the Ollama prefix, empty to disable", stringSetting(func(c *Config) *string { return &c.Ollama.URL })},
+	{"OLLAMA_MODEL", "ollama-model", "Ollama model used by LLM features", stringSetting(func(c *Config) *string { return &c.Ollama.Model })},
+	{"OLLAMA_PREFIX", "ollama-prefix", "path the Ollama proxy is mounted under", stringSetting(func(c *Config) *string { return &c.Ollama.Prefix })},
+	{"OLLAMA_TIMEOUT", "ollama-timeout", "how long to wait for Ollama response headers", durationSetting(func(c *Config) *Duration { return &c.Ollama.Timeout })},
+	{"OLLAMA_DIAL_TIMEOUT", "ollama-dial-timeout", "how long to wait for a connection to Ollama", durationSetting(func(c *Config) *Duration { return &c.Ollama.DialTimeout })},
+
+	{"CORRECTION_ENABLED", "correction", "allow LLM correction of OCRed pages with correct=true", boolSetting(func(c *Config) *bool { return &c.Correction.Enabled })},
+	{"CORRECTION_MIN_CONFIDENCE", "correction-min-confidence", "layout confidence at which pages are not corrected", floatSetting(func(c *Config) *float64 { return &c.Correction.MinConfidence })},
+	{"CORRECTION_TIMEOUT", "correction-timeout", "timeout of the LLM correction of a page", durationSetting(func(c *Config) *Duration { return &c.Correction.Timeout })},
+	{"EXTRACTION_TIMEOUT", "extraction-timeout", "timeout of the LLM request of a field extraction", durationSetting(func(c *Config) *Duration { return &c.Extraction.Timeout })},
+}
+
+// Load builds the configuration from defaults, the file named by -config or CONFIG_FILE,
+// environment variables read through lookupEnv, and the command-line args, then validates it.
+// Empty environment variables clear string settings and are ignored for the others.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	fs := flag.NewFlagSet("ocr-server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	path := fs.String("config", "", "YAML or TOML config file")
+	flags := make(map[string]*flagValue)
+	for _, s := range settings {
+		if s.flag != "" {
+			flags[s.flag] = &flagValue{boolean: s.value.boolean}
+			fs.Var(flags[s.flag], s.flag, s.usage)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	cfg := Default()
+	if *path == "" {
+		*path, _ = lookupEnv(FileEnv)
+	}
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		value, ok := lookupEnv(s.env)
+		if ok && (value != "" || s.value.text) {
+			if err := s.value.set(cfg, value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", s.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && flagErr == nil {
+				if err := s.value.set(cfg, flags[s.flag].raw); err != nil {
+					flagErr = fmt.Errorf("invalid -%s: %w", s.flag, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// loadFile reads a YAML (.yaml, .yml) or TOML (.toml) file over cfg. Unknown keys are rejected.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalWithOptions(data, c, yaml.Strict())
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(c)
+		var strict *toml.StrictMissingError
+		if errors.As(err, &strict) {
+			err = fmt.Errorf("unknown keys:\n%s", strict.String())
+		}
+	default:
+		return fmt.Errorf("config %s: unsupported format %q, use .yaml, .yml or .toml", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	return nil
+}
+
+// flagValue holds a flag's raw value, which the setting parses after the file and environment.
+type flagValue struct {
+	raw     string
+	boolean bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.raw
+}
+
+func (f *flagValue) Set(value string) error {
+	f.raw = value
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value, such as -cache-disabled.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.boolean
+}
+
+func stringSetting(field func(*Config) *string) valueSetter {
+	return valueSetter{text: true, set: func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+func intSetting(field func(*Config) *int) valueSetter {
+	return valueSetter{set: func(c *Config, value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func int64Setting(field func(*Config) *int64) valueSetter {
+	return valueSetter{set: func(c *Config, value string) error {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func floatSetting(field func(*Config) *float64) valueSetter {
+	return valueSetter{set: func(c *Config, value string) error {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func boolSetting(field func(*Config) *bool) valueSetter {
+	return valueSetter{boolean: true, set: func(c *Config, value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("not a boolean")
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func durationSetting(field func(*Config) *Duration) valueSetter {
+	return valueSetter{set: func(c *Config, value string) error {
+		return field(c).UnmarshalText([]byte(value))
+	}}
+}
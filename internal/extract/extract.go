@@ -0,0 +1,140 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"app/internal/llm"
+	"app/internal/ocr"
+)
+
+// Extraction methods.
+const (
+	MethodAuto  = "auto"  // The LLM when available, with rules filling the fields it missed
+	MethodLLM   = "llm"   // Only the LLM
+	MethodRules = "rules" // Only the rule-based extractors
+)
+
+// ErrInvalidMethod is returned for unknown methods and for MethodLLM without a model.
+var ErrInvalidMethod = errors.New("invalid extraction method")
+
+// Candidate is a possible value of a field and the page it was found on.
+type Candidate struct {
+	Value any // A string from the text, or any JSON value from an LLM
+	Page  int // 0 when unknown
+}
+
+// Finder finds candidate values for the fields of a schema, best first.
+type Finder interface {
+	Find(ctx context.Context, schema *Schema, pages []ocr.PageContent) (map[string][]Candidate, error)
+}
+
+// Value is the extracted value of a field.
+type Value struct {
+	Value  any    `json:"value"`            // nil when not found
+	Page   int    `json:"page,omitempty"`   // Page the value was found on
+	Method string `json:"method,omitempty"` // MethodLLM or MethodRules
+	Error  string `json:"error,omitempty"`  // Why no value was found
+}
+
+// Result is the outcome of an extraction.
+type Result struct {
+	Fields map[string]Value `json:"fields"`
+	Valid  bool             `json:"valid"`            // Every required field has a valid value
+	Errors []string         `json:"errors,omitempty"` // Problems with required fields
+}
+
+// Extractor fills schemas from OCR pages with rules and, when configured, an LLM.
+type Extractor struct {
+	Rules Finder
+	LLM   Finder // nil when no model is configured
+}
+
+// NewExtractor returns an Extractor using the rule-based extractors and, when gen is
+// non-nil, an LLM.
+func NewExtractor(gen llm.Generator) *Extractor {
+	e := &Extractor{Rules: Rules{}}
+	if gen != nil {
+		e.LLM = &LLM{Generator: gen}
+	}
+	return e
+}
+
+// CheckMethod reports whether method can be used, so requests fail before OCR starts.
+func (e *Extractor) CheckMethod(method string) error {
+	switch method {
+	case "", MethodAuto, MethodRules:
+		return nil
+	case MethodLLM:
+		if e.LLM == nil {
+			return fmt.Errorf("%w: no llm is configured", ErrInvalidMethod)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w %q, use %s, %s or %s", ErrInvalidMethod, method, MethodAuto, MethodLLM, MethodRules)
+}
+
+type namedFinder struct {
+	method string
+	finder Finder
+}
+
+// Extract fills schema from pages with method (default: MethodAuto). Each field takes
+// the first candidate that passes validation. With MethodAuto an LLM failure falls back
+// to rules; with MethodLLM it is returned.
+func (e *Extractor) Extract(ctx context.Context, schema *Schema, pages []ocr.PageContent, method string) (Result, error) {
+	if err := e.CheckMethod(method); err != nil {
+		return Result{}, err
+	}
+
+	var finders []namedFinder
+	switch method {
+	case MethodLLM:
+		finders = []namedFinder{{MethodLLM, e.LLM}}
+	case MethodRules:
+		finders = []namedFinder{{MethodRules, e.Rules}}
+	default:
+		if e.LLM != nil {
+			finders = append(finders, namedFinder{MethodLLM, e.LLM})
+		}
+		finders = append(finders, namedFinder{MethodRules, e.Rules})
+	}
+
+	candidates := make([]map[string][]Candidate, len(finders))
+	for i, f := range finders {
+		found, err := f.finder.Find(ctx, schema, pages)
+		if err != nil {
+			if method == MethodLLM || ctx.Err() != nil {
+				return Result{}, err
+			}
+			log.Printf("extract: %s: %v; falling back to rules", f.method, err)
+			continue
+		}
+		candidates[i] = found
+	}
+
+	result := Result{Fields: make(map[string]Value, len(schema.Fields)), Valid: true}
+	for _, field := range schema.Fields {
+		value := Value{Error: errNotFound.Error()}
+	search:
+		for i, f := range finders {
+			for _, c := range candidates[i][field.Name] {
+				coerced, err := field.Coerce(c.Value)
+				if err != nil {
+					value.Error = err.Error()
+					continue
+				}
+				value = Value{Value: coerced, Page: c.Page, Method: f.method}
+				break search
+			}
+		}
+		if value.Error != "" && field.Required {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s", field.Name, value.Error))
+		}
+		result.Fields[field.Name] = value
+	}
+	return result, nil
+}
@@ -0,0 +1,141 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"app/internal/ocr"
+)
+
+var invoicePages = []ocr.PageContent{
+	{Page: 1, Content: "ACME Corp Invoice No: INV-2041 Date: 5 March 2024 Currency USD"},
+	{Page: 2, Content: "Items: 3 Subtotal: 1,000.00 Tax: 100.00 Total: 1,100.00 Paid: no"},
+}
+
+func mustSchema(t *testing.T, data string) *Schema {
+	t.Helper()
+	schema, err := ParseSchema([]byte(data))
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	return schema
+}
+
+func TestExtractor_Rules(t *testing.T) {
+	result, err := NewExtractor(nil).Extract(context.Background(), mustSchema(t, invoiceSchema), invoicePages, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Valid || len(result.Errors) != 0 {
+		t.Fatalf("expected a valid result, got %+v", result)
+	}
+
+	expected := map[string]Value{
+		"invoice_number": {Value: "INV-2041", Page: 1, Method: MethodRules},
+		"issue_date":     {Value: "2024-03-05", Page: 1, Method: MethodRules},
+		"currency":       {Value: "USD", Page: 1, Method: MethodRules},
+		"items":          {Value: int64(3), Page: 2, Method: MethodRules},
+		"total":          {Value: 1100.0, Page: 2, Method: MethodRules},
+		"paid":           {Value: false, Page: 2, Method: MethodRules},
+	}
+	for name, want := range expected {
+		if got := result.Fields[name]; got != want {
+			t.Errorf("%s: expected %+v, got %+v", name, want, got)
+		}
+	}
+}
+
+func TestExtractor_MissingRequiredField(t *testing.T) {
+	pages := []ocr.PageContent{{Page: 1, Content: "Invoice No: 2041 Total: soon"}}
+
+	result, err := NewExtractor(nil).Extract(context.Background(), mustSchema(t, invoiceSchema), pages, MethodRules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid || len(result.Errors) != 2 {
+		t.Fatalf("expected both required fields to fail, got %+v", result)
+	}
+	if v := result.Fields["currency"]; v.Value != nil || v.Error != "not found" {
+		t.Fatalf("expected optional field not found, got %+v", v)
+	}
+	if v := result.Fields["total"]; v.Value != nil || !strings.Contains(v.Error, "not found") {
+		t.Fatalf("expected missing total, got %+v", v)
+	}
+}
+
+type stubGenerator struct {
+	answer string
+	err    error
+	prompt string
+}
+
+func (s *stubGenerator) Generate(ctx context.Context, prompt string) (string, error) {
+	s.prompt = prompt
+	return s.answer, s.err
+}
+
+func TestExtractor_LLM(t *testing.T) {
+	gen := &stubGenerator{answer: `Here you go: {
+		"invoice_number": {"value": "INV-2041", "page": 2},
+		"issue_date": {"value": "2024-03-05", "page": 1},
+		"total": {"value": 1100, "page": 2},
+		"currency": {"value": "EUR", "page": 1},
+		"items": 3,
+		"paid": {"value": null, "page": 0}
+	}`}
+
+	result, err := NewExtractor(gen).Extract(context.Background(), mustSchema(t, invoiceSchema), invoicePages, MethodAuto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]Value{
+		// The model named the wrong page; the value is only on page 1
+		"invoice_number": {Value: "INV-2041", Page: 1, Method: MethodLLM},
+		"issue_date":     {Value: "2024-03-05", Page: 1, Method: MethodLLM},
+		"total":          {Value: 1100.0, Page: 2, Method: MethodLLM},
+		// EUR is not in the document, so rules fill it in
+		"currency": {Value: "USD", Page: 1, Method: MethodRules},
+		"items":    {Value: int64(3), Method: MethodLLM},
+		"paid":     {Value: false, Page: 2, Method: MethodRules},
+	}
+	for name, want := range expected {
+		if got := result.Fields[name]; got != want {
+			t.Errorf("%s: expected %+v, got %+v", name, want, got)
+		}
+	}
+	if !strings.Contains(gen.prompt, "=== Page 2 ===") || !strings.Contains(gen.prompt, "total (number): Amount due including tax") {
+		t.Fatalf("unexpected prompt:\n%s", gen.prompt)
+	}
+}
+
+func TestExtractor_LLMFailure(t *testing.T) {
+	schema := mustSchema(t, invoiceSchema)
+	gen := &stubGenerator{err: errors.New("connection refused")}
+
+	// Auto falls back to rules
+	result, err := NewExtractor(gen).Extract(context.Background(), schema, invoicePages, MethodAuto)
+	if err != nil || result.Fields["total"].Method != MethodRules {
+		t.Fatalf("expected rules fallback, got %+v %v", result, err)
+	}
+
+	if _, err := NewExtractor(gen).Extract(context.Background(), schema, invoicePages, MethodLLM); err == nil {
+		t.Fatal("expected llm failure to be returned for method llm")
+	}
+}
+
+func TestExtractor_CheckMethod(t *testing.T) {
+	if err := NewExtractor(nil).CheckMethod(MethodLLM); !errors.Is(err, ErrInvalidMethod) {
+		t.Fatalf("expected llm to be unavailable without a model, got %v", err)
+	}
+	if err := NewExtractor(&stubGenerator{}).CheckMethod("magic"); !errors.Is(err, ErrInvalidMethod) {
+		t.Fatalf("expected unknown method to fail, got %v", err)
+	}
+	for _, method := range []string{"", MethodAuto, MethodRules, MethodLLM} {
+		if err := NewExtractor(&stubGenerator{}).CheckMethod(method); err != nil {
+			t.Fatalf("%q: unexpected error %v", method, err)
+		}
+	}
+}
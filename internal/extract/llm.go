@@ -0,0 +1,154 @@
+package extract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"app/internal/llm"
+	"app/internal/ocr"
+)
+
+// defaultMaxChars bounds the document text sent to the model.
+const defaultMaxChars = 24000
+
+// extractionPrompt asks for every field with the page it was read from.
+const extractionPrompt = `Extract the fields below from the document text. The text of each page follows a "=== Page N ===" line.
+
+Fields:
+%s
+Reply with a JSON object mapping every field name to {"value": <value>, "page": <page number>}. Use null as the value when the document does not contain the field. Copy values as they are written; do not guess or compute them.
+
+Document:
+%s`
+
+// LLM asks a language model to fill the schema from the page text.
+type LLM struct {
+	Generator llm.Generator // Should answer in JSON, e.g. an llm.Ollama with Format "json"
+	MaxChars  int           // Document characters sent to the model (default: 24000)
+}
+
+// llmAnswer is the answer for one field.
+type llmAnswer struct {
+	Value any
+	Page  int
+}
+
+// Find returns at most one candidate per field. String values that do not appear in the
+// document are dropped as made up, and the page of the others is checked against pages.
+func (l *LLM) Find(ctx context.Context, schema *Schema, pages []ocr.PageContent) (map[string][]Candidate, error) {
+	answer, err := l.Generator.Generate(ctx, l.prompt(schema, pages))
+	if err != nil {
+		return nil, err
+	}
+	start, end := strings.IndexByte(answer, '{'), strings.LastIndexByte(answer, '}')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: answer is not a JSON object", llm.ErrUnavailable)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &fields); err != nil {
+		return nil, fmt.Errorf("%w: decode answer: %v", llm.ErrUnavailable, err)
+	}
+
+	found := make(map[string][]Candidate)
+	for _, field := range schema.Fields {
+		raw, ok := fields[field.Name]
+		if !ok {
+			continue
+		}
+		a, err := parseAnswer(raw)
+		if err != nil || a.Value == nil {
+			continue
+		}
+		if page, ok := locate(field, a, pages); ok {
+			found[field.Name] = []Candidate{{Value: a.Value, Page: page}}
+		}
+	}
+	return found, nil
+}
+
+func (l *LLM) prompt(schema *Schema, pages []ocr.PageContent) string {
+	var fields strings.Builder
+	for _, field := range schema.Fields {
+		kind := field.Type
+		switch {
+		case field.Format == FormatDate:
+			kind += ", date as YYYY-MM-DD"
+		case len(field.Enum) > 0:
+			kind += ", one of " + strings.Join(field.Enum, ", ")
+		}
+		fmt.Fprintf(&fields, "- %s (%s)", field.Name, kind)
+		if field.Description != "" {
+			fmt.Fprintf(&fields, ": %s", field.Description)
+		}
+		fields.WriteByte('\n')
+	}
+
+	maxChars := l.MaxChars
+	if maxChars <= 0 {
+		maxChars = defaultMaxChars
+	}
+	var document strings.Builder
+	for _, page := range pages {
+		fmt.Fprintf(&document, "=== Page %d ===\n%s\n", page.Page, page.Content)
+	}
+	return fmt.Sprintf(extractionPrompt, fields.String(), truncate(document.String(), maxChars))
+}
+
+// parseAnswer reads {"value": ..., "page": N}, or a bare value without its page.
+func parseAnswer(raw json.RawMessage) (llmAnswer, error) {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return llmAnswer{}, err
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return llmAnswer{Value: value}, nil
+	}
+	if _, ok := obj["value"]; !ok {
+		return llmAnswer{}, errors.New("answer has no value")
+	}
+	a := llmAnswer{Value: obj["value"]}
+	if page, ok := obj["page"].(float64); ok {
+		a.Page = int(page)
+	}
+	return a, nil
+}
+
+// locate returns the page an answer came from. Plain strings must appear in the text:
+// on the page the model named, or else the first page holding them. Other values keep
+// the named page if it exists, or report page 0 when it does not.
+func locate(field Field, a llmAnswer, pages []ocr.PageContent) (int, bool) {
+	s, isString := a.Value.(string)
+	if !isString || field.Type != TypeString || field.Format == FormatDate {
+		for _, page := range pages {
+			if page.Page == a.Page {
+				return a.Page, true
+			}
+		}
+		return 0, true
+	}
+
+	needle := searchable(s)
+	if needle == "" {
+		return 0, false
+	}
+	for _, page := range pages {
+		if page.Page == a.Page && strings.Contains(searchable(page.Content), needle) {
+			return page.Page, true
+		}
+	}
+	for _, page := range pages {
+		if strings.Contains(searchable(page.Content), needle) {
+			return page.Page, true
+		}
+	}
+	return 0, false
+}
+
+// searchable lowercases s and collapses its whitespace for substring checks.
+func searchable(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
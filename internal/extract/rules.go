@@ -0,0 +1,130 @@
+package extract
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"app/internal/ocr"
+)
+
+// labelWindow is how many characters after a keyword are searched for its value. The
+// window also ends at a line break or at the next keyword of any field, since collapsed
+// text has no line breaks to keep one field's window out of the next.
+const labelWindow = 120
+
+var (
+	// labelSeparator is skipped between a keyword and its value, e.g. ": " or " # ".
+	labelSeparator = regexp.MustCompile(`^[\s:#=.\-–—]*`)
+
+	dateValue    = regexp.MustCompile(`^(?:\d{4}-\d{2}-\d{2}|\d{1,2}[./-]\d{1,2}[./-]\d{4}|\d{1,2}[ -][A-Za-z]{3,9}[ -]\d{4}|[A-Za-z]{3,9} \d{1,2},? \d{4})`)
+	numberValue  = regexp.MustCompile(`^(?:[A-Za-z]{1,3}\.?\s*|[$€£¥]\s*)?[-+]?\d(?:[\d.,']*\d)?`)
+	booleanValue = regexp.MustCompile(`^(?i:yes|no|true|false)\b`)
+	wordValue    = regexp.MustCompile(`^\S+`)
+)
+
+// Rules finds values after their keywords in the page text, such as "Invoice number: INV-7",
+// and, for fields with a pattern or enum, anywhere on the page. String fields without a
+// pattern or enum take the single word after the keyword.
+type Rules struct{}
+
+// Find returns the candidates of every field in page order, keyword matches first.
+func (Rules) Find(ctx context.Context, schema *Schema, pages []ocr.PageContent) (map[string][]Candidate, error) {
+	var labels []*regexp.Regexp
+	for _, field := range schema.Fields {
+		labels = append(labels, field.labels...)
+	}
+
+	found := make(map[string][]Candidate)
+	for _, field := range schema.Fields {
+		var labelled, anywhere []Candidate
+		for _, page := range pages {
+			for _, value := range field.afterLabels(page.Content, labels) {
+				labelled = append(labelled, Candidate{Value: value, Page: page.Page})
+			}
+			for _, value := range field.onPage(page.Content) {
+				anywhere = append(anywhere, Candidate{Value: value, Page: page.Page})
+			}
+		}
+		found[field.Name] = append(labelled, anywhere...)
+	}
+	return found, ctx.Err()
+}
+
+// afterLabels returns the value following each occurrence of a keyword in text, looking
+// no further than the next of stops, the keywords of every field.
+func (f Field) afterLabels(text string, stops []*regexp.Regexp) []string {
+	var values []string
+	for _, label := range f.labels {
+		for _, loc := range label.FindAllStringIndex(text, -1) {
+			window := truncate(text[loc[1]:], labelWindow)
+			if end := strings.IndexByte(window, '\n'); end >= 0 {
+				window = window[:end]
+			}
+			for _, stop := range stops {
+				if next := stop.FindStringIndex(window); next != nil {
+					window = window[:next[0]]
+				}
+			}
+			window = labelSeparator.ReplaceAllString(window, "")
+			if value := f.leadingValue(window); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// leadingValue returns the value of the field's type at the start of window.
+func (f Field) leadingValue(window string) string {
+	switch {
+	case f.search != nil:
+		if loc := f.search.FindStringIndex(window); loc != nil {
+			return window[loc[0]:loc[1]]
+		}
+		return ""
+	case len(f.Enum) > 0:
+		return f.enumValue(window)
+	case f.Format == FormatDate:
+		return dateValue.FindString(window)
+	case f.Type == TypeNumber || f.Type == TypeInteger:
+		return strings.TrimSpace(numberValue.FindString(window))
+	case f.Type == TypeBoolean:
+		return booleanValue.FindString(window)
+	}
+	return strings.TrimRight(wordValue.FindString(window), ".,;")
+}
+
+// onPage returns values found anywhere in text, which only fields with a pattern or enum have.
+func (f Field) onPage(text string) []string {
+	if f.search != nil {
+		return f.search.FindAllString(text, -1)
+	}
+	if value := f.enumValue(text); value != "" {
+		return []string{value}
+	}
+	return nil
+}
+
+// enumValue returns the enum value appearing first in text as a whole word.
+func (f Field) enumValue(text string) string {
+	best, bestAt := "", -1
+	for _, allowed := range f.Enum {
+		if allowed == "" {
+			continue
+		}
+		if loc := labelPattern(allowed).FindStringIndex(text); loc != nil && (bestAt < 0 || loc[0] < bestAt) {
+			best, bestAt = text[loc[0]:loc[1]], loc[0]
+		}
+	}
+	return best
+}
+
+// truncate returns at most n runes of s.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
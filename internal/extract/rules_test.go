@@ -0,0 +1,48 @@
+package extract
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+
+	"app/internal/ocr"
+)
+
+func TestRules_LabelWindowEndsAtNextKeyword(t *testing.T) {
+	schema := mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"status": {"type": "string", "enum": ["open", "void"], "x-keywords": ["Status"]},
+			"reference": {"type": "string", "pattern": "^[A-Z]{2}\\d{4}$", "x-keywords": ["Reference"]},
+			"order": {"type": "string", "x-keywords": ["Order"]}
+		}
+	}`)
+
+	// Collapsed text has no line breaks between fields
+	text := "Status: pending Reference: n/a Order: AB1234 void copy"
+	var labels []*regexp.Regexp
+	for _, field := range schema.Fields {
+		labels = append(labels, field.labels...)
+	}
+
+	expected := map[string][]string{
+		"status":    nil,
+		"reference": nil,
+		"order":     {"AB1234"},
+	}
+	for _, field := range schema.Fields {
+		if got := field.afterLabels(text, labels); !slices.Equal(got, expected[field.Name]) {
+			t.Errorf("%s: expected %q after its keyword, got %q", field.Name, expected[field.Name], got)
+		}
+	}
+
+	// Values past the next keyword are still found anywhere on the page, after keyword matches
+	found, err := Rules{}.Find(context.Background(), schema, []ocr.PageContent{{Page: 1, Content: text}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := found["reference"]; len(got) != 1 || got[0].Value != "AB1234" {
+		t.Fatalf("expected only the page-wide reference match, got %+v", got)
+	}
+}
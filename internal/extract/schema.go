@@ -0,0 +1,183 @@
+// Package extract fills a JSON schema of fields, such as invoice numbers, dates and totals,
+// from the OCR text of a document.
+package extract
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidSchema is returned for schemas that cannot be parsed or use unsupported features.
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// Field types accepted in a schema property.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+)
+
+// FormatDate marks string fields holding a date, returned as YYYY-MM-DD.
+const FormatDate = "date"
+
+// MaxFields limits the number of properties in a schema.
+const MaxFields = 50
+
+var fieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// Schema is the set of fields to extract, parsed from a JSON schema of type object.
+type Schema struct {
+	Fields []Field // Sorted by name
+}
+
+// Field is one property of a Schema.
+type Field struct {
+	Name        string
+	Type        string // TypeString, TypeNumber, TypeInteger or TypeBoolean
+	Format      string // FormatDate or empty
+	Description string
+	Pattern     *regexp.Regexp // Values must match; also used to find string values
+	Enum        []string       // Allowed string values
+	Keywords    []string       // Labels preceding the value in the text, from x-keywords or the name
+	Required    bool
+
+	search *regexp.Regexp   // Pattern without its anchors, to find values in running text
+	labels []*regexp.Regexp // Keywords matched case-insensitively on word boundaries
+}
+
+// property is the JSON form of a Field. Keywords not listed are ignored.
+type property struct {
+	Type        string   `json:"type"`
+	Format      string   `json:"format"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Pattern     string   `json:"pattern"`
+	Enum        []string `json:"enum"`
+	Keywords    []string `json:"x-keywords"`
+}
+
+// ParseSchema parses a JSON schema with "properties" of scalar types and an optional
+// "required" list. Each property may set "format": "date", "pattern", a string "enum",
+// and "x-keywords", the labels rule-based extraction looks for.
+func ParseSchema(data []byte) (*Schema, error) {
+	var doc struct {
+		Type       string              `json:"type"`
+		Properties map[string]property `json:"properties"`
+		Required   []string            `json:"required"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	if doc.Type != "" && doc.Type != "object" {
+		return nil, fmt.Errorf("%w: type must be object", ErrInvalidSchema)
+	}
+	if len(doc.Properties) == 0 {
+		return nil, fmt.Errorf("%w: no properties", ErrInvalidSchema)
+	}
+	if len(doc.Properties) > MaxFields {
+		return nil, fmt.Errorf("%w: more than %d properties", ErrInvalidSchema, MaxFields)
+	}
+	for _, name := range doc.Required {
+		if _, ok := doc.Properties[name]; !ok {
+			return nil, fmt.Errorf("%w: required property %q is not defined", ErrInvalidSchema, name)
+		}
+	}
+
+	schema := &Schema{}
+	for name, prop := range doc.Properties {
+		field, err := newField(name, prop, slices.Contains(doc.Required, name))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSchema, name, err)
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	sort.Slice(schema.Fields, func(i, j int) bool { return schema.Fields[i].Name < schema.Fields[j].Name })
+	return schema, nil
+}
+
+func newField(name string, prop property, required bool) (Field, error) {
+	if !fieldName.MatchString(name) {
+		return Field{}, errors.New("name must be letters, digits and underscores")
+	}
+	field := Field{
+		Name:        name,
+		Type:        prop.Type,
+		Format:      prop.Format,
+		Description: cmp.Or(prop.Description, prop.Title),
+		Enum:        prop.Enum,
+		Keywords:    prop.Keywords,
+		Required:    required,
+	}
+	if field.Type == "" {
+		field.Type = TypeString
+	}
+
+	switch field.Type {
+	case TypeString, TypeNumber, TypeInteger, TypeBoolean:
+	default:
+		return Field{}, fmt.Errorf("unsupported type %q", field.Type)
+	}
+	if field.Format != "" && (field.Format != FormatDate || field.Type != TypeString) {
+		return Field{}, fmt.Errorf("unsupported format %q", field.Format)
+	}
+	if len(field.Enum) > 0 && field.Type != TypeString {
+		return Field{}, errors.New("enum is only supported for strings")
+	}
+	if prop.Pattern != "" {
+		if field.Type != TypeString {
+			return Field{}, errors.New("pattern is only supported for strings")
+		}
+		pattern, err := regexp.Compile(prop.Pattern)
+		if err != nil {
+			return Field{}, fmt.Errorf("invalid pattern: %v", err)
+		}
+		field.Pattern = pattern
+		field.search = pattern
+		if search, err := regexp.Compile(strings.TrimSuffix(strings.TrimPrefix(prop.Pattern, "^"), "$")); err == nil {
+			field.search = search
+		}
+	}
+
+	if len(field.Keywords) == 0 {
+		field.Keywords = []string{strings.ReplaceAll(name, "_", " ")}
+		if prop.Title != "" {
+			field.Keywords = append([]string{prop.Title}, field.Keywords...)
+		}
+	}
+	for _, keyword := range field.Keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			field.labels = append(field.labels, labelPattern(keyword))
+		}
+	}
+	return field, nil
+}
+
+// labelPattern matches keyword case-insensitively, with any run of spaces between its
+// words, and on word boundaries where the keyword starts or ends with an ASCII letter or
+// digit, the characters \b knows about.
+func labelPattern(keyword string) *regexp.Regexp {
+	words := strings.Fields(keyword)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	expr := strings.Join(words, `\s+`)
+	if isWordByte(keyword[0]) {
+		expr = `\b` + expr
+	}
+	if isWordByte(keyword[len(keyword)-1]) {
+		expr += `\b`
+	}
+	return regexp.MustCompile(`(?i)` + expr)
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
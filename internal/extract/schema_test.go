@@ -0,0 +1,92 @@
+package extract
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const invoiceSchema = `{
+	"type": "object",
+	"properties": {
+		"invoice_number": {"type": "string", "pattern": "^INV-\\d+$", "x-keywords": ["Invoice No", "Invoice #"]},
+		"issue_date": {"type": "string", "format": "date", "title": "Date"},
+		"total": {"type": "number", "description": "Amount due including tax"},
+		"currency": {"type": "string", "enum": ["USD", "IDR"]},
+		"items": {"type": "integer", "x-keywords": ["Items"]},
+		"paid": {"type": "boolean"}
+	},
+	"required": ["invoice_number", "total"]
+}`
+
+func TestParseSchema(t *testing.T) {
+	schema, err := ParseSchema([]byte(invoiceSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, f := range schema.Fields {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "currency,invoice_number,issue_date,items,paid,total" {
+		t.Fatalf("expected fields sorted by name, got %v", names)
+	}
+
+	date := schema.Fields[2]
+	if date.Format != FormatDate || date.Description != "Date" || strings.Join(date.Keywords, ",") != "Date,issue date" || date.Required {
+		t.Fatalf("unexpected date field: %+v", date)
+	}
+	total := schema.Fields[5]
+	if total.Type != TypeNumber || !total.Required || total.Keywords[0] != "total" {
+		t.Fatalf("unexpected total field: %+v", total)
+	}
+	number := schema.Fields[1]
+	if number.Pattern == nil || number.search.String() != `INV-\d+` {
+		t.Fatalf("expected unanchored search pattern, got %v", number.search)
+	}
+}
+
+func TestParseSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"malformed", `{"properties":`},
+		{"not an object", `{"type": "array", "properties": {"a": {}}}`},
+		{"no properties", `{"type": "object"}`},
+		{"nested object", `{"properties": {"address": {"type": "object"}}}`},
+		{"bad pattern", `{"properties": {"a": {"pattern": "("}}}`},
+		{"pattern on number", `{"properties": {"a": {"type": "number", "pattern": "\\d"}}}`},
+		{"unknown format", `{"properties": {"a": {"format": "email"}}}`},
+		{"undefined required", `{"properties": {"a": {}}, "required": ["b"]}`},
+		{"bad name", `{"properties": {"a b": {}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSchema([]byte(tt.schema)); !errors.Is(err, ErrInvalidSchema) {
+				t.Fatalf("expected ErrInvalidSchema, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLabelPattern(t *testing.T) {
+	tests := []struct {
+		keyword string
+		text    string
+		match   bool
+	}{
+		{"invoice number", "INVOICE  NUMBER: 7", true},
+		{"total", "Subtotal: 5", false},
+		{"Invoice #", "Invoice #123", true},
+		{"Invoice #", "Invoice # 123", true},
+	}
+
+	for _, tt := range tests {
+		if got := labelPattern(tt.keyword).MatchString(tt.text); got != tt.match {
+			t.Errorf("%q in %q: expected %v, got %v", tt.keyword, tt.text, tt.match, got)
+		}
+	}
+}
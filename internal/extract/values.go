@@ -0,0 +1,180 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNotFound marks fields for which no value was found.
+var errNotFound = errors.New("not found")
+
+// Coerce converts a found value, a string from the text or any JSON value from an LLM,
+// to the field type and checks it against the field's format, pattern and enum.
+// Numbers are returned as float64, integers as int64 and dates as YYYY-MM-DD strings.
+func (f Field) Coerce(value any) (any, error) {
+	if value == nil {
+		return nil, errNotFound
+	}
+	if s, ok := value.(string); ok {
+		value = strings.TrimSpace(s)
+		if value == "" {
+			return nil, errNotFound
+		}
+	}
+
+	switch f.Type {
+	case TypeNumber, TypeInteger:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case string:
+			parsed, err := parseNumber(v)
+			if err != nil {
+				return nil, err
+			}
+			n = parsed
+		default:
+			return nil, fmt.Errorf("expected a number, got %T", value)
+		}
+		if f.Type == TypeNumber {
+			return n, nil
+		}
+		if n != math.Trunc(n) {
+			return nil, fmt.Errorf("expected an integer, got %v", n)
+		}
+		return int64(n), nil
+
+	case TypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(v) {
+			case "true", "yes", "y":
+				return true, nil
+			case "false", "no", "n":
+				return false, nil
+			}
+		}
+		return nil, fmt.Errorf("expected a boolean, got %v", value)
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("expected a string, got %T", value)
+	}
+	if f.Format == FormatDate {
+		date, err := parseDate(s)
+		if err != nil {
+			return nil, err
+		}
+		s = date
+	}
+	if f.Pattern != nil && !f.Pattern.MatchString(s) {
+		return nil, fmt.Errorf("%q does not match pattern %s", s, f.Pattern)
+	}
+	if len(f.Enum) > 0 {
+		for _, allowed := range f.Enum {
+			if strings.EqualFold(s, allowed) {
+				return allowed, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", s, strings.Join(f.Enum, ", "))
+	}
+	return s, nil
+}
+
+var (
+	numberPattern   = regexp.MustCompile(`^[-+]?\d[\d.,' ]*$`)
+	currencyPattern = regexp.MustCompile(`^(?i:[A-Z]{1,3}\.?\s*|[$€£¥]\s*)|\s+(?i:[A-Z]{1,3})$|\s*[$€£¥]$`)
+)
+
+// parseNumber parses amounts such as "1,234.56", "1.234,56", "Rp 1.500.000" or "$ 99".
+// With a single separator, a group of exactly three digits after it is read as thousands.
+func parseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(currencyPattern.ReplaceAllString(strings.TrimSpace(s), ""))
+	if !numberPattern.MatchString(s) {
+		return 0, fmt.Errorf("expected a number, got %q", s)
+	}
+	s = strings.NewReplacer(" ", "", "'", "").Replace(s)
+
+	lastComma, lastDot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		// The separator used last is the decimal one
+		if lastComma > lastDot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastComma >= 0 || lastDot >= 0:
+		sep := ","
+		if lastDot >= 0 {
+			sep = "."
+		}
+		last := max(lastComma, lastDot)
+		if strings.Count(s, sep) > 1 || len(s)-last-1 == 3 {
+			s = strings.ReplaceAll(s, sep, "")
+		} else {
+			s = strings.Replace(s, sep, ".", 1)
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number, got %q", s)
+	}
+	return n, nil
+}
+
+// dateLayouts are the textual dates parseDate accepts, besides numeric day, month and year.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2 January 2006",
+	"2 Jan 2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"January 2 2006",
+	"Jan 2 2006",
+	"2-Jan-2006",
+}
+
+var numericDate = regexp.MustCompile(`^(\d{1,2})[./-](\d{1,2})[./-](\d{4})$`)
+
+// parseDate parses a date and formats it as YYYY-MM-DD. Numeric dates are read day first
+// unless only month first makes them valid.
+func parseDate(s string) (string, error) {
+	s = strings.Join(strings.Fields(s), " ")
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+
+	if m := numericDate.FindStringSubmatch(s); m != nil {
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[2])
+		year, _ := strconv.Atoi(m[3])
+		day, month := a, b
+		if month > 12 {
+			day, month = b, a
+		}
+		t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if month <= 12 && t.Day() == day {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("expected a date, got %q", s)
+}
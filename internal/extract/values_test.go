@@ -0,0 +1,96 @@
+package extract
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"1,234.56", 1234.56},
+		{"1.234,56", 1234.56},
+		{"Rp 1.500.000", 1500000},
+		{"$ 99", 99},
+		{"12.50", 12.5},
+		{"1,5", 1.5},
+		{"1,500", 1500},
+		{"-42", -42},
+		{"100 USD", 100},
+	}
+
+	for _, tt := range tests {
+		got, err := parseNumber(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: expected %v, got %v %v", tt.in, tt.want, got, err)
+		}
+	}
+
+	for _, in := range []string{"", "twelve", "12abc"} {
+		if _, err := parseNumber(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2024-03-05", "2024-03-05"},
+		{"05/03/2024", "2024-03-05"},
+		{"12/31/2024", "2024-12-31"},
+		{"5.3.2024", "2024-03-05"},
+		{"5 March 2024", "2024-03-05"},
+		{"Mar 5, 2024", "2024-03-05"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: expected %s, got %s %v", tt.in, tt.want, got, err)
+		}
+	}
+
+	for _, in := range []string{"31/31/2024", "30/02/2024", "soon"} {
+		if _, err := parseDate(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestField_Coerce(t *testing.T) {
+	tests := []struct {
+		name  string
+		field Field
+		in    any
+		want  any
+		ok    bool
+	}{
+		{"number from text", Field{Type: TypeNumber}, "1,250.00", 1250.0, true},
+		{"number from json", Field{Type: TypeNumber}, 12.5, 12.5, true},
+		{"integer", Field{Type: TypeInteger}, "3", int64(3), true},
+		{"fractional integer", Field{Type: TypeInteger}, 2.5, nil, false},
+		{"boolean", Field{Type: TypeBoolean}, "Yes", true, true},
+		{"date", Field{Type: TypeString, Format: FormatDate}, "5 March 2024", "2024-03-05", true},
+		{"pattern", Field{Type: TypeString, Pattern: regexp.MustCompile(`^INV-\d+$`)}, "INV-17", "INV-17", true},
+		{"pattern mismatch", Field{Type: TypeString, Pattern: regexp.MustCompile(`^INV-\d+$`)}, "17", nil, false},
+		{"enum", Field{Type: TypeString, Enum: []string{"USD", "IDR"}}, "usd", "USD", true},
+		{"enum mismatch", Field{Type: TypeString, Enum: []string{"USD", "IDR"}}, "EUR", nil, false},
+		{"string from number", Field{Type: TypeString}, 42.0, "42", true},
+		{"missing", Field{Type: TypeString}, nil, nil, false},
+		{"blank", Field{Type: TypeString}, "  ", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.Coerce(tt.in)
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Fatalf("expected %v (ok=%v), got %v %v", tt.want, tt.ok, got, err)
+			}
+		})
+	}
+}
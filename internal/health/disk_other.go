@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "errors"
+
+// freeSpace is not implemented outside unix systems.
+func freeSpace(dir string) (uint64, error) {
+	return 0, errors.New("free disk space check not supported on this platform")
+}
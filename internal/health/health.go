@@ -0,0 +1,197 @@
+// Package health runs readiness checks against the OCR toolchain and the host.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"app/internal/ocr"
+)
+
+// Check statuses.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Overall readiness reported in Report.Status.
+const (
+	ReportReady    = "ready"
+	ReportDegraded = "degraded" // Only optional checks failed
+	ReportNotReady = "not_ready"
+)
+
+// Check is a single named readiness check. A failing required check makes the service not ready.
+type Check struct {
+	Name     string
+	Required bool
+	Run      func(ctx context.Context) (string, error) // Returns a detail shown on success
+}
+
+// Result is the outcome of one Check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Required   bool   `json:"required"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of every check, in the order they were configured.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// Ready reports whether every required check passed.
+func (r Report) Ready() bool {
+	return r.Status != ReportNotReady
+}
+
+// Checker runs its checks concurrently, each bounded by Timeout.
+type Checker struct {
+	Checks  []Check
+	Timeout time.Duration // Per check (default: 5s)
+}
+
+// NewChecker creates a Checker for the given checks.
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{Checks: checks}
+}
+
+// Check runs every check and summarizes the results.
+func (c *Checker) Check(ctx context.Context) Report {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	results := make([]Result, len(c.Checks))
+	var wg sync.WaitGroup
+	for i, check := range c.Checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, check, timeout)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: ReportReady, Checks: results}
+	for _, r := range results {
+		if r.Status == StatusOK {
+			continue
+		}
+		if r.Required {
+			report.Status = ReportNotReady
+			break
+		}
+		report.Status = ReportDegraded
+	}
+	return report
+}
+
+func run(ctx context.Context, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check.Run(ctx)
+	result := Result{
+		Name:       check.Name,
+		Status:     StatusOK,
+		Required:   check.Required,
+		Detail:     detail,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = ""
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Binary checks that binary is on PATH and reports its absolute path.
+func Binary(binary string, required bool) Check {
+	return Check{
+		Name:     binary,
+		Required: required,
+		Run: func(ctx context.Context) (string, error) {
+			return ocr.ResolveBinary(binary)
+		},
+	}
+}
+
+// Languages checks that tesseract lists every language in lang, a "+" joined list.
+// Running tesseract also catches broken installs and unreadable language data.
+func Languages(binary, lang string) Check {
+	return Check{
+		Name:     "languages",
+		Required: true,
+		Run: func(ctx context.Context) (string, error) {
+			languages, err := ocr.ListLanguages(ctx, binary)
+			if err != nil {
+				return "", err
+			}
+			if err := languages.Validate(lang); err != nil {
+				return "", err
+			}
+			return lang, nil
+		},
+	}
+}
+
+// TempDir checks that a file can be created in dir, or os.TempDir when dir is empty.
+func TempDir(dir string) Check {
+	return Check{
+		Name:     "temp_dir",
+		Required: true,
+		Run: func(ctx context.Context) (string, error) {
+			dir := orTempDir(dir)
+			f, err := os.CreateTemp(dir, "ocr-readyz-*")
+			if err != nil {
+				return "", err
+			}
+			name := f.Name()
+			_, err = f.Write([]byte("ok"))
+			err = errors.Join(err, f.Close(), os.Remove(name))
+			if err != nil {
+				return "", err
+			}
+			return dir, nil
+		},
+	}
+}
+
+// DiskSpace checks that the filesystem holding dir, or os.TempDir when dir is empty,
+// has at least minFree bytes available.
+func DiskSpace(dir string, minFree uint64) Check {
+	return Check{
+		Name:     "disk_space",
+		Required: true,
+		Run: func(ctx context.Context) (string, error) {
+			dir := orTempDir(dir)
+			free, err := freeSpace(dir)
+			if err != nil {
+				return "", err
+			}
+			if free < minFree {
+				return "", fmt.Errorf("%s has %d MB free, need %d MB", dir, free>>20, minFree>>20)
+			}
+			return fmt.Sprintf("%d MB free", free>>20), nil
+		},
+	}
+}
+
+func orTempDir(dir string) string {
+	if dir == "" {
+		return os.TempDir()
+	}
+	return dir
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func staticCheck(name string, required bool, err error) Check {
+	return Check{Name: name, Required: required, Run: func(ctx context.Context) (string, error) {
+		return "fine", err
+	}}
+}
+
+func TestChecker_Status(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name   string
+		checks []Check
+		status string
+		ready  bool
+	}{
+		{"all ok", []Check{staticCheck("a", true, nil), staticCheck("b", false, nil)}, ReportReady, true},
+		{"optional failed", []Check{staticCheck("a", true, nil), staticCheck("b", false, boom)}, ReportDegraded, true},
+		{"required failed", []Check{staticCheck("a", true, boom), staticCheck("b", false, boom)}, ReportNotReady, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewChecker(tt.checks...).Check(context.Background())
+			if report.Status != tt.status || report.Ready() != tt.ready {
+				t.Fatalf("unexpected report: %+v", report)
+			}
+			if len(report.Checks) != len(tt.checks) || report.Checks[0].Name != "a" || report.Checks[1].Name != "b" {
+				t.Fatalf("expected results in check order: %+v", report.Checks)
+			}
+		})
+	}
+}
+
+func TestChecker_ResultDetails(t *testing.T) {
+	report := NewChecker(staticCheck("ok", true, nil), staticCheck("bad", true, errors.New("boom"))).Check(context.Background())
+
+	if r := report.Checks[0]; r.Status != StatusOK || r.Detail != "fine" || r.Error != "" {
+		t.Errorf("unexpected ok result: %+v", r)
+	}
+	if r := report.Checks[1]; r.Status != StatusFail || r.Detail != "" || r.Error != "boom" {
+		t.Errorf("unexpected failed result: %+v", r)
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	slow := Check{Name: "slow", Required: true, Run: func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}}
+	checker := &Checker{Checks: []Check{slow}, Timeout: 20 * time.Millisecond}
+
+	report := checker.Check(context.Background())
+	if report.Ready() || !strings.Contains(report.Checks[0].Error, "deadline") {
+		t.Fatalf("expected timed out check, got %+v", report)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fake-tool")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if detail, err := Binary(path, true).Run(context.Background()); err != nil || detail != path {
+		t.Fatalf("expected %s, got %q, %v", path, detail, err)
+	}
+	if _, err := Binary(filepath.Join(dir, "missing"), true).Run(context.Background()); err == nil {
+		t.Fatal("expected missing binary to fail")
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "tesseract")
+	script := "#!/bin/sh\nprintf 'List of available languages (3):\\neng\\nind\\nosd\\n'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Languages(binary, "eng+ind").Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Languages(binary, "eng+chi_sim").Run(context.Background()); err == nil || !strings.Contains(err.Error(), "chi_sim") {
+		t.Fatalf("expected missing chi_sim, got %v", err)
+	}
+}
+
+func TestTempDir(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := TempDir(dir).Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected probe file to be removed, found %d entries", len(entries))
+	}
+	if _, err := TempDir(filepath.Join(dir, "missing")).Run(context.Background()); err == nil {
+		t.Fatal("expected missing dir to fail")
+	}
+}
+
+func TestDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := DiskSpace(dir, 1).Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := DiskSpace(dir, 1<<62).Run(context.Background()); err == nil {
+		t.Fatal("expected not enough free space")
+	}
+}
@@ -0,0 +1,140 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore persists each job as a JSON document in a directory.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a FileStore rooted at dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create job store dir: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Create stores a new job.
+func (s *FileStore) Create(ctx context.Context, job *Job) error {
+	if !validID(job.ID) {
+		return fmt.Errorf("invalid job id %q", job.ID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(s.path(job.ID)); err == nil {
+		return fmt.Errorf("job %s already exists", job.ID)
+	}
+	return s.write(job)
+}
+
+// Get reads a job from disk.
+func (s *FileStore) Get(ctx context.Context, id string) (*Job, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(s.path(id))
+}
+
+// Update replaces an existing job.
+func (s *FileStore) Update(ctx context.Context, job *Job) error {
+	if !validID(job.ID) {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(s.path(job.ID)); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return s.write(job)
+}
+
+// Delete removes a job from disk.
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(id)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete job: %w", err)
+	}
+	return nil
+}
+
+// List reads all jobs in the directory.
+func (s *FileStore) List(ctx context.Context) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	jobs := make([]*Job, 0, len(paths))
+	for _, path := range paths {
+		job, err := s.read(path)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// path returns the file for a job.
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *FileStore) read(path string) (*Job, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("read job: %w", err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("decode job %s: %w", filepath.Base(path), err)
+	}
+	return &job, nil
+}
+
+// write stores the job atomically via a temp file and rename.
+func (s *FileStore) write(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, "job-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create job file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write job file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close job file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(job.ID)); err != nil {
+		return fmt.Errorf("commit job file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package job
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"app/internal/ocr"
+	"app/internal/webhook"
+)
+
+// Status is the lifecycle state of a job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Finished reports whether the status is terminal.
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+// Progress tracks per-page completion of a running job.
+type Progress struct {
+	PagesDone      int   `json:"pages_done"`
+	PagesTotal     int   `json:"pages_total"`
+	CompletedPages []int `json:"completed_pages,omitempty"`
+}
+
+// Callback is where the outcome of a job is POSTed once it finishes.
+type Callback struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"` // Signs the payload with HMAC-SHA256 when set
+}
+
+// Request describes a document submitted for asynchronous OCR.
+type Request struct {
+	Filename      string
+	Options       ocr.Options
+	Callback      *Callback // Notified once the job succeeds or fails when non-nil
+	SearchablePDF bool      // Also produce a searchable PDF of the document
+}
+
+// Job is an asynchronous OCR run over an uploaded document.
+type Job struct {
+	ID         string            `json:"id"`
+	Status     Status            `json:"status"`
+	Filename   string            `json:"filename"`
+	Options    ocr.Options       `json:"options"`
+	InputPath  string            `json:"input_path"`
+	OutputPath string            `json:"output_path,omitempty"` // Searchable PDF, when requested
+	Progress   Progress          `json:"progress"`
+	Pages      []ocr.PageContent `json:"pages,omitempty"`
+	Summary    *ocr.Summary      `json:"summary,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Callback   *Callback         `json:"callback,omitempty"`
+	Deliveries []webhook.Attempt `json:"deliveries,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// clone returns a deep copy so stored jobs cannot be mutated through returned pointers.
+func (j *Job) clone() *Job {
+	c := *j
+	c.Progress.CompletedPages = append([]int(nil), j.Progress.CompletedPages...)
+	c.Pages = append([]ocr.PageContent(nil), j.Pages...)
+	c.Deliveries = append([]webhook.Attempt(nil), j.Deliveries...)
+	if j.Summary != nil {
+		summary := *j.Summary
+		summary.Languages = append([]string(nil), j.Summary.Languages...)
+		c.Summary = &summary
+	}
+	if j.Callback != nil {
+		cb := *j.Callback
+		c.Callback = &cb
+	}
+	if j.StartedAt != nil {
+		t := *j.StartedAt
+		c.StartedAt = &t
+	}
+	if j.FinishedAt != nil {
+		t := *j.FinishedAt
+		c.FinishedAt = &t
+	}
+	return &c
+}
+
+// newID returns a random 128-bit hex identifier.
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validID reports whether id looks like an identifier produced by newID.
+// It keeps user-supplied IDs from addressing arbitrary files.
+func validID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,528 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"app/internal/ocr"
+	"app/internal/webhook"
+)
+
+var (
+	// ErrFinished is returned when cancelling a job that already reached a terminal status.
+	ErrFinished = errors.New("job already finished")
+	// ErrNotFinished is returned when deleting a job that is still queued or running.
+	ErrNotFinished = errors.New("job not finished")
+	// ErrQueueFull is returned when the queue cannot accept more jobs.
+	ErrQueueFull = errors.New("job queue is full")
+)
+
+// errSkip aborts an update without treating it as a failure.
+var errSkip = errors.New("skip update")
+
+// Runner processes a job's input document.
+type Runner func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error)
+
+// Config controls the Manager's queue and retention.
+type Config struct {
+	Workers   int               // Jobs processed concurrently (default: 1)
+	QueueSize int               // Jobs waiting to be processed (default: 100)
+	DataDir   string            // Where uploaded inputs are kept until processed (required)
+	Retention time.Duration     // How long finished jobs are kept (default: 24h)
+	Notifier  *webhook.Notifier // Delivers job callbacks (default: webhook.NewNotifier())
+}
+
+// Manager runs jobs from an in-process queue and records them in a Store.
+type Manager struct {
+	store  Store
+	run    Runner
+	config Config
+	queue  chan string
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+
+	stop  context.CancelFunc // Interrupts running jobs
+	drain context.CancelFunc // Stops workers from taking queued jobs
+	wg    sync.WaitGroup
+}
+
+// NewManager creates a Manager. Call Start to begin processing.
+func NewManager(store Store, run Runner, cfg Config) (*Manager, error) {
+	if cfg.DataDir == "" {
+		return nil, errors.New("job data dir is required")
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	if cfg.Notifier == nil {
+		cfg.Notifier = webhook.NewNotifier()
+	}
+	if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create job data dir: %w", err)
+	}
+
+	return &Manager{
+		store:   store,
+		run:     run,
+		config:  cfg,
+		queue:   make(chan string, cfg.QueueSize),
+		cancels: make(map[string]context.CancelFunc),
+	}, nil
+}
+
+// Start requeues unfinished jobs left in the store and starts the workers.
+func (m *Manager) Start(ctx context.Context) error {
+	pending, err := m.recover(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, m.stop = context.WithCancel(ctx)
+	dequeueCtx, drain := context.WithCancel(ctx)
+	m.drain = drain
+	for i := 0; i < m.config.Workers; i++ {
+		m.wg.Add(1)
+		go m.worker(ctx, dequeueCtx)
+	}
+
+	m.wg.Add(1)
+	go m.janitor(dequeueCtx)
+
+	// Requeue without blocking startup when more jobs are pending than the queue holds
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for _, id := range pending {
+			select {
+			case m.queue <- id:
+			case <-dequeueCtx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close stops the workers and waits for them to exit. Running jobs are
+// interrupted and left queued so they resume on the next Start.
+func (m *Manager) Close() {
+	if m.stop != nil {
+		m.stop()
+	}
+	m.wg.Wait()
+}
+
+// Shutdown stops the workers from taking queued jobs and waits for running jobs to
+// finish. Jobs still running when ctx is done are interrupted as by Close, and ctx's
+// error is returned. Queued jobs are left for the next Start.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	if m.drain != nil {
+		m.drain()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		m.Close()
+		return ctx.Err()
+	}
+}
+
+// Submit stores the input document and queues a new job for it. Callback URLs that are
+// invalid or do not resolve to addresses the notifier may reach are rejected.
+func (m *Manager) Submit(ctx context.Context, r io.Reader, req Request) (*Job, error) {
+	if req.Callback != nil {
+		if err := m.config.Notifier.CheckURL(ctx, req.Callback.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("generate job id: %w", err)
+	}
+
+	inputPath := filepath.Join(m.config.DataDir, id+".pdf")
+	if err := writeInput(inputPath, r); err != nil {
+		return nil, err
+	}
+
+	// Keep encrypted documents decrypted on disk so the password is never stored. Without a
+	// password this only rejects encrypted documents up front; unreadable ones fail when run.
+	err = decryptInput(inputPath, req.Options.Password)
+	if req.Options.Password == "" && errors.Is(err, ocr.ErrInvalidPDF) {
+		err = nil
+	}
+	if err != nil {
+		os.Remove(inputPath)
+		return nil, err
+	}
+	req.Options.Password = ""
+
+	now := time.Now().UTC()
+	job := &Job{
+		ID:        id,
+		Status:    StatusQueued,
+		Filename:  req.Filename,
+		Options:   req.Options,
+		InputPath: inputPath,
+		Callback:  req.Callback,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if req.SearchablePDF {
+		job.OutputPath = filepath.Join(m.config.DataDir, id+".ocr.pdf")
+	}
+	if err := m.store.Create(ctx, job); err != nil {
+		os.Remove(inputPath)
+		return nil, fmt.Errorf("create job: %w", err)
+	}
+
+	select {
+	case m.queue <- id:
+	default:
+		m.store.Delete(ctx, id)
+		os.Remove(inputPath)
+		return nil, ErrQueueFull
+	}
+
+	return job, nil
+}
+
+// Get returns the current state of a job.
+func (m *Manager) Get(ctx context.Context, id string) (*Job, error) {
+	return m.store.Get(ctx, id)
+}
+
+// Cancel stops a queued or running job.
+func (m *Manager) Cancel(ctx context.Context, id string) (*Job, error) {
+	var cancelled *Job
+	err := m.update(ctx, id, func(job *Job) error {
+		if job.Status.Finished() {
+			return ErrFinished
+		}
+		job.Status = StatusCancelled
+		finish(job)
+		if cancel, ok := m.cancels[id]; ok {
+			cancel()
+		}
+		cancelled = job.clone()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	os.Remove(cancelled.InputPath)
+	removeOutput(cancelled)
+	return cancelled, nil
+}
+
+// Delete removes a finished job.
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !job.Status.Finished() {
+		return ErrNotFinished
+	}
+	os.Remove(job.InputPath)
+	removeOutput(job)
+	return m.store.Delete(ctx, id)
+}
+
+// worker processes queued jobs under ctx until dequeueCtx is done.
+func (m *Manager) worker(ctx, dequeueCtx context.Context) {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-dequeueCtx.Done():
+			return
+		case id := <-m.queue:
+			// Both may be ready; a job taken while draining stays queued in the store
+			if dequeueCtx.Err() != nil {
+				return
+			}
+			m.process(ctx, id)
+		}
+	}
+}
+
+// process runs a single job and records its outcome.
+func (m *Manager) process(ctx context.Context, id string) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var job *Job
+	err := m.update(ctx, id, func(j *Job) error {
+		if j.Status != StatusQueued {
+			return errSkip
+		}
+		now := time.Now().UTC()
+		j.Status = StatusRunning
+		j.StartedAt = &now
+		j.Progress = Progress{}
+		m.cancels[id] = cancel
+		job = j.clone()
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, errSkip) && !errors.Is(err, ErrNotFound) {
+			log.Printf("job %s: start: %v", id, err)
+		}
+		return
+	}
+
+	opts := job.Options
+	opts.SearchablePDF = job.OutputPath
+	opts.OnProgress = func(p ocr.Progress) {
+		m.update(ctx, id, func(j *Job) error {
+			j.Progress.PagesDone = p.Done
+			j.Progress.PagesTotal = p.Total
+			if p.Page != nil {
+				j.Progress.CompletedPages = append(j.Progress.CompletedPages, p.Page.Page)
+			}
+			return nil
+		})
+	}
+
+	pages, runErr := m.run(jobCtx, job.InputPath, opts)
+
+	var finished *Job
+	err = m.update(context.Background(), id, func(j *Job) error {
+		delete(m.cancels, id)
+		switch {
+		case j.Status == StatusCancelled:
+			removeOutput(j)
+			return errSkip
+		case ctx.Err() != nil:
+			// Shutting down: leave the job queued so it resumes after restart
+			j.Status = StatusQueued
+			j.StartedAt = nil
+			return nil
+		case runErr != nil:
+			j.Status = StatusFailed
+			j.Error = runErr.Error()
+			removeOutput(j)
+		default:
+			summary := ocr.Summarize(pages)
+			j.Status = StatusSucceeded
+			j.Pages = pages
+			j.Summary = &summary
+		}
+		finish(j)
+		finished = j.clone()
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSkip) {
+		log.Printf("job %s: finish: %v", id, err)
+	}
+
+	if finished != nil && finished.Callback != nil {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.notify(ctx, finished)
+		}()
+	}
+
+	if ctx.Err() == nil {
+		os.Remove(job.InputPath)
+	}
+}
+
+// callbackPayload is the JSON body POSTed to a job's callback URL.
+type callbackPayload struct {
+	ID         string            `json:"id"`
+	Status     Status            `json:"status"`
+	Filename   string            `json:"filename"`
+	Pages      []ocr.PageContent `json:"pages,omitempty"`
+	Summary    *ocr.Summary      `json:"summary,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// notify delivers the job outcome to its callback URL and records every attempt on the job.
+func (m *Manager) notify(ctx context.Context, job *Job) {
+	payload, err := json.Marshal(callbackPayload{
+		ID:         job.ID,
+		Status:     job.Status,
+		Filename:   job.Filename,
+		Pages:      job.Pages,
+		Summary:    job.Summary,
+		Error:      job.Error,
+		FinishedAt: job.FinishedAt,
+	})
+	if err != nil {
+		log.Printf("job %s: encode callback: %v", job.ID, err)
+		return
+	}
+
+	record := func(a webhook.Attempt) {
+		err := m.update(context.Background(), job.ID, func(j *Job) error {
+			j.Deliveries = append(j.Deliveries, a)
+			return nil
+		})
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.Printf("job %s: record delivery: %v", job.ID, err)
+		}
+	}
+
+	if err := m.config.Notifier.Deliver(ctx, job.Callback.URL, job.Callback.Secret, payload, record); err != nil {
+		log.Printf("job %s: callback: %v", job.ID, err)
+	}
+}
+
+// update applies fn to the stored job under the manager lock.
+func (m *Manager) update(ctx context.Context, id string, fn func(*Job) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := fn(job); err != nil {
+		return err
+	}
+	job.UpdatedAt = time.Now().UTC()
+	return m.store.Update(ctx, job)
+}
+
+// recover returns the IDs of unfinished jobs whose input is still available
+// and fails the rest.
+func (m *Manager) recover(ctx context.Context) ([]string, error) {
+	jobs, err := m.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+
+	var pending []string
+	for _, job := range jobs {
+		if job.Status.Finished() {
+			continue
+		}
+		err := m.update(ctx, job.ID, func(j *Job) error {
+			if _, err := os.Stat(j.InputPath); err != nil {
+				j.Status = StatusFailed
+				j.Error = "input lost before processing"
+				finish(j)
+				return nil
+			}
+			j.Status = StatusQueued
+			j.StartedAt = nil
+			pending = append(pending, j.ID)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("recover job %s: %w", job.ID, err)
+		}
+	}
+	return pending, nil
+}
+
+// janitor periodically removes finished jobs older than the retention period.
+func (m *Manager) janitor(ctx context.Context) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(min(m.config.Retention, time.Hour))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.prune(ctx)
+		}
+	}
+}
+
+// prune deletes finished jobs whose retention has expired.
+func (m *Manager) prune(ctx context.Context) {
+	jobs, err := m.store.List(ctx)
+	if err != nil {
+		log.Printf("job janitor: %v", err)
+		return
+	}
+	cutoff := time.Now().Add(-m.config.Retention)
+	for _, job := range jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			if err := m.Delete(ctx, job.ID); err != nil && !errors.Is(err, ErrNotFound) {
+				log.Printf("job janitor: delete %s: %v", job.ID, err)
+			}
+		}
+	}
+}
+
+// finish stamps the job's completion time.
+func finish(job *Job) {
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+}
+
+// removeOutput deletes the job's searchable PDF, if any.
+func removeOutput(job *Job) {
+	if job.OutputPath != "" {
+		os.Remove(job.OutputPath)
+	}
+}
+
+// writeInput copies the uploaded document to path.
+func writeInput(path string, r io.Reader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create job input: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("write job input: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("close job input: %w", err)
+	}
+	return nil
+}
+
+// decryptInput replaces the job input at path with a decrypted copy when it is encrypted.
+func decryptInput(path, password string) error {
+	decrypted := path + ".decrypted"
+	ok, err := ocr.DecryptPDF(path, decrypted, password)
+	if err != nil || !ok {
+		return err
+	}
+	if err := os.Rename(decrypted, path); err != nil {
+		os.Remove(decrypted)
+		return fmt.Errorf("store decrypted job input: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,533 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"app/internal/ocr"
+	"app/internal/webhook"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func TestManager_RunsJob(t *testing.T) {
+	ctx := context.Background()
+	var gotOpts ocr.Options
+	var gotInput []byte
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		gotOpts = opts
+		gotInput, _ = os.ReadFile(inputPath)
+		opts.OnProgress(ocr.Progress{Done: 0, Total: 2})
+		opts.OnProgress(ocr.Progress{Done: 1, Total: 2, Page: &ocr.PageContent{Page: 2}})
+		opts.OnProgress(ocr.Progress{Done: 2, Total: 2, Page: &ocr.PageContent{Page: 1}})
+		return []ocr.PageContent{
+			{Page: 1, Content: "one", Source: ocr.SourceTextLayer, Chars: 3},
+			{Page: 2, Content: "two", Source: ocr.SourceOCR, Chars: 3},
+		}, nil
+	}
+	m := startManager(t, NewMemoryStore(), run)
+
+	job, err := m.Submit(ctx, strings.NewReader("%PDF-1.4"), Request{Filename: "doc.pdf", Options: ocr.Options{Language: "eng"}})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if job.Status != StatusQueued || job.ID == "" {
+		t.Fatalf("unexpected submitted job: %+v", job)
+	}
+
+	done := waitForStatus(t, m, job.ID, StatusSucceeded)
+	if len(done.Pages) != 2 || done.Pages[1].Content != "two" {
+		t.Fatalf("unexpected pages: %+v", done.Pages)
+	}
+	if done.Progress.PagesDone != 2 || done.Progress.PagesTotal != 2 || len(done.Progress.CompletedPages) != 2 {
+		t.Fatalf("unexpected progress: %+v", done.Progress)
+	}
+	if done.Summary == nil || done.Summary.OCRPages != 1 || done.Summary.Chars != 6 {
+		t.Fatalf("unexpected summary: %+v", done.Summary)
+	}
+	if done.StartedAt == nil || done.FinishedAt == nil {
+		t.Fatalf("expected timestamps, got %+v", done)
+	}
+	if gotOpts.Language != "eng" || string(gotInput) != "%PDF-1.4" {
+		t.Fatalf("runner got opts=%+v input=%q", gotOpts, gotInput)
+	}
+	if _, err := os.Stat(done.InputPath); !os.IsNotExist(err) {
+		t.Fatalf("expected input cleanup, got %v", err)
+	}
+}
+
+func TestManager_SearchablePDF(t *testing.T) {
+	ctx := context.Background()
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		if opts.SearchablePDF == "" {
+			return nil, errors.New("searchable pdf path not set")
+		}
+		return []ocr.PageContent{{Page: 1, Content: "one"}}, os.WriteFile(opts.SearchablePDF, []byte("%PDF-1.7"), 0o644)
+	}
+	m := startManager(t, NewMemoryStore(), run)
+
+	job, err := m.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "doc.pdf", SearchablePDF: true})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	done := waitForStatus(t, m, job.ID, StatusSucceeded)
+	if data, err := os.ReadFile(done.OutputPath); err != nil || string(data) != "%PDF-1.7" {
+		t.Fatalf("expected searchable pdf at %q, got %q %v", done.OutputPath, data, err)
+	}
+
+	if err := m.Delete(ctx, job.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := os.Stat(done.OutputPath); !os.IsNotExist(err) {
+		t.Fatalf("expected searchable pdf removed with the job, got %v", err)
+	}
+}
+
+func TestManager_DecryptsInput(t *testing.T) {
+	ctx := context.Background()
+	seen := make(chan ocr.Options, 1)
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		seen <- opts
+		if _, err := api.PageCountFile(inputPath); err != nil {
+			return nil, err
+		}
+		return []ocr.PageContent{{Page: 1, Content: "one"}}, nil
+	}
+	m := startManager(t, NewMemoryStore(), run)
+	data := encryptedPDF(t, "secret")
+
+	if _, err := m.Submit(ctx, strings.NewReader(data), Request{}); !errors.Is(err, ocr.ErrPasswordRequired) {
+		t.Fatalf("expected ErrPasswordRequired without a password, got %v", err)
+	}
+	if _, err := m.Submit(ctx, strings.NewReader(data), Request{Options: ocr.Options{Password: "wrong"}}); !errors.Is(err, ocr.ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+
+	job, err := m.Submit(ctx, strings.NewReader(data), Request{Options: ocr.Options{Password: "secret"}})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if job.Options.Password != "" {
+		t.Fatal("expected the password not to be stored with the job")
+	}
+	waitForStatus(t, m, job.ID, StatusSucceeded)
+	if opts := <-seen; opts.Password != "" {
+		t.Fatal("expected the job to run on the decrypted input without a password")
+	}
+}
+
+func TestManager_DeliversCallback(t *testing.T) {
+	ctx := context.Background()
+	received := make(chan []byte, 1)
+	var signature string
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(webhook.SignatureHeader)
+		received <- body
+	}))
+	defer ts.Close()
+
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		return []ocr.PageContent{{Page: 1, Content: "called back"}}, nil
+	}
+	m, err := NewManager(NewMemoryStore(), run, Config{
+		DataDir: t.TempDir(),
+		Notifier: &webhook.Notifier{
+			AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+			MaxAttempts:     3,
+			InitialBackoff:  time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := m.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer m.Close()
+
+	if _, err := startManager(t, NewMemoryStore(), run).Submit(ctx, strings.NewReader("pdf"), Request{Callback: &Callback{URL: ts.URL}}); !errors.Is(err, webhook.ErrNonPublicAddress) {
+		t.Fatalf("expected loopback callbacks to be rejected by default, got %v", err)
+	}
+	job, err := m.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "doc.pdf", Callback: &Callback{URL: ts.URL, Secret: "shh"}})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	var body []byte
+	select {
+	case body = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not delivered")
+	}
+
+	var payload struct {
+		ID     string            `json:"id"`
+		Status Status            `json:"status"`
+		Pages  []ocr.PageContent `json:"pages"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.ID != job.ID || payload.Status != StatusSucceeded || payload.Pages[0].Content != "called back" {
+		t.Fatalf("unexpected payload: %s", body)
+	}
+	if !webhook.Verify("shh", body, signature) {
+		t.Fatalf("invalid signature %q", signature)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := m.Get(ctx, job.ID)
+		if len(got.Deliveries) == 2 {
+			if got.Deliveries[0].StatusCode != http.StatusServiceUnavailable || got.Deliveries[1].StatusCode != http.StatusOK {
+				t.Fatalf("unexpected deliveries: %+v", got.Deliveries)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 recorded deliveries, got %+v", got.Deliveries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestManager_RecordsFailure(t *testing.T) {
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		return nil, errors.New("engine exploded")
+	}
+	m := startManager(t, NewMemoryStore(), run)
+
+	job, err := m.Submit(context.Background(), strings.NewReader("pdf"), Request{Filename: "doc.pdf"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	failed := waitForStatus(t, m, job.ID, StatusFailed)
+	if failed.Error != "engine exploded" {
+		t.Fatalf("unexpected error: %q", failed.Error)
+	}
+}
+
+func TestManager_CancelRunningJob(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	}
+	m := startManager(t, NewMemoryStore(), run)
+
+	job, err := m.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "doc.pdf"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+
+	cancelled, err := m.Cancel(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancelled.Status != StatusCancelled {
+		t.Fatalf("expected cancelled status, got %s", cancelled.Status)
+	}
+
+	select {
+	case err := <-stopped:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected runner context cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner was not cancelled")
+	}
+
+	// The worker must not overwrite the cancellation
+	time.Sleep(50 * time.Millisecond)
+	got, _ := m.Get(ctx, job.ID)
+	if got.Status != StatusCancelled {
+		t.Fatalf("expected job to stay cancelled, got %s", got.Status)
+	}
+
+	if _, err := m.Cancel(ctx, job.ID); !errors.Is(err, ErrFinished) {
+		t.Fatalf("expected ErrFinished cancelling twice, got %v", err)
+	}
+	if err := m.Delete(ctx, job.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := m.Get(ctx, job.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestManager_CancelQueuedJob(t *testing.T) {
+	ctx := context.Background()
+	called := false
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		called = true
+		return nil, nil
+	}
+	// Not started, so the job stays queued
+	m, err := NewManager(NewMemoryStore(), run, Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	job, err := m.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "doc.pdf"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if err := m.Delete(ctx, job.ID); !errors.Is(err, ErrNotFinished) {
+		t.Fatalf("expected ErrNotFinished deleting queued job, got %v", err)
+	}
+	if _, err := m.Cancel(ctx, job.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	m.process(ctx, job.ID)
+	if called {
+		t.Fatal("cancelled job must not run")
+	}
+}
+
+func TestManager_QueueFull(t *testing.T) {
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		return nil, nil
+	}
+	dataDir := t.TempDir()
+	m, err := NewManager(NewMemoryStore(), run, Config{DataDir: dataDir, QueueSize: 1})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	if _, err := m.Submit(context.Background(), strings.NewReader("pdf"), Request{Filename: "a.pdf"}); err != nil {
+		t.Fatalf("first submit: %v", err)
+	}
+	if _, err := m.Submit(context.Background(), strings.NewReader("pdf"), Request{Filename: "b.pdf"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+
+	inputs, _ := filepath.Glob(filepath.Join(dataDir, "*.pdf"))
+	if len(inputs) != 1 {
+		t.Fatalf("expected rejected input to be removed, got %v", inputs)
+	}
+}
+
+func TestManager_ResumesJobsAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	storeDir := t.TempDir()
+	dataDir := t.TempDir()
+
+	store, err := NewFileStore(storeDir)
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+
+	blocked := make(chan struct{})
+	first, err := NewManager(store, func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		close(blocked)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, Config{DataDir: dataDir})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := first.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	job, err := first.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "doc.pdf"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-blocked
+	first.Close()
+
+	interrupted, err := store.Get(ctx, job.ID)
+	if err != nil || interrupted.Status != StatusQueued {
+		t.Fatalf("expected interrupted job to be requeued, got %+v %v", interrupted, err)
+	}
+
+	reopened, err := NewFileStore(storeDir)
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	second := startManager(t, reopened, func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		return []ocr.PageContent{{Page: 1, Content: "resumed"}}, nil
+	})
+
+	done := waitForStatus(t, second, job.ID, StatusSucceeded)
+	if done.Pages[0].Content != "resumed" {
+		t.Fatalf("unexpected pages: %+v", done.Pages)
+	}
+}
+
+func TestManager_ShutdownDrainsRunningJob(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		close(started) // Only the first job may run
+		<-release
+		return []ocr.PageContent{{Page: 1, Content: "drained"}}, nil
+	}
+	m := startManager(t, NewMemoryStore(), run)
+
+	running, err := m.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "one.pdf"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+	queued, err := m.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "two.pdf"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- m.Shutdown(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Fatalf("shutdown: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not return after the running job finished")
+	}
+
+	if got, _ := m.Get(ctx, running.ID); got.Status != StatusSucceeded {
+		t.Fatalf("expected running job to finish, got %s", got.Status)
+	}
+	if got, _ := m.Get(ctx, queued.ID); got.Status != StatusQueued {
+		t.Fatalf("expected queued job to stay queued, got %s", got.Status)
+	}
+}
+
+func TestManager_ShutdownInterruptsAfterDeadline(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{})
+	run := func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	m := startManager(t, NewMemoryStore(), run)
+
+	job, err := m.Submit(ctx, strings.NewReader("pdf"), Request{Filename: "doc.pdf"})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+
+	deadline, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(deadline); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// Interrupted jobs resume after restart
+	if got, _ := m.Get(ctx, job.ID); got.Status != StatusQueued {
+		t.Fatalf("expected interrupted job to be requeued, got %s", got.Status)
+	}
+}
+
+func TestManager_Prune(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	m, err := NewManager(store, nil, Config{DataDir: t.TempDir(), Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+
+	old := newTestJob(t)
+	old.Status = StatusSucceeded
+	finishedAt := time.Now().Add(-2 * time.Hour)
+	old.FinishedAt = &finishedAt
+	recent := newTestJob(t)
+	recent.Status = StatusSucceeded
+	finish(recent)
+	for _, job := range []*Job{old, recent} {
+		if err := store.Create(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m.prune(ctx)
+
+	if _, err := store.Get(ctx, old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected expired job pruned, got %v", err)
+	}
+	if _, err := store.Get(ctx, recent.ID); err != nil {
+		t.Errorf("expected recent job kept, got %v", err)
+	}
+}
+
+func startManager(t *testing.T, store Store, run Runner) *Manager {
+	t.Helper()
+	m, err := NewManager(store, run, Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("start manager: %v", err)
+	}
+	t.Cleanup(m.Close)
+	return m
+}
+
+func waitForStatus(t *testing.T, m *Manager, id string, status Status) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	job, _ := m.Get(context.Background(), id)
+	t.Fatalf("timed out waiting for %s, job is %+v", status, job)
+	return nil
+}
+
+// encryptedPDF returns a single blank page PDF encrypted with password.
+func encryptedPDF(t *testing.T, password string) string {
+	t.Helper()
+	const plain = "%PDF-1.4\n" +
+		"1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n" +
+		"2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n" +
+		"3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> >>\nendobj\n" +
+		"trailer\n<< /Root 1 0 R >>\n%%EOF\n"
+
+	var out strings.Builder
+	conf := model.NewAESConfiguration(password, password+"-owner", 256)
+	if err := api.Encrypt(strings.NewReader(plain), &out, conf); err != nil {
+		t.Fatalf("encrypt pdf: %v", err)
+	}
+	return out.String()
+}
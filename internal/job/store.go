@@ -0,0 +1,18 @@
+package job
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a job does not exist in the store.
+var ErrNotFound = errors.New("job not found")
+
+// Store persists jobs.
+type Store interface {
+	Create(ctx context.Context, job *Job) error
+	Get(ctx context.Context, id string) (*Job, error)
+	Update(ctx context.Context, job *Job) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*Job, error)
+}
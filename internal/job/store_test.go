@@ -0,0 +1,138 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"app/internal/ocr"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "jobs"))
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	testStore(t, store)
+}
+
+func TestFileStore_PersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	first, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	job := newTestJob(t)
+	job.Pages = []ocr.PageContent{{Page: 1, Content: "persisted"}}
+	if err := first.Create(ctx, job); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	second, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("reopen file store: %v", err)
+	}
+	got, err := second.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(got.Pages) != 1 || got.Pages[0].Content != "persisted" || got.Options.Language != "eng" {
+		t.Fatalf("unexpected job after reopen: %+v", got)
+	}
+}
+
+func TestFileStore_RejectsInvalidIDs(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "jobs"))
+	if err != nil {
+		t.Fatalf("new file store: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "secret.json"), []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"", "../secret", "not-hex"} {
+		if _, err := store.Get(context.Background(), id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) expected ErrNotFound, got %v", id, err)
+		}
+	}
+}
+
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	job := newTestJob(t)
+	if err := store.Create(ctx, job); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := store.Create(ctx, job); err == nil {
+		t.Fatal("expected error creating duplicate job")
+	}
+
+	got, err := store.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != StatusQueued || got.Filename != "doc.pdf" {
+		t.Fatalf("unexpected job: %+v", got)
+	}
+
+	// Mutating a returned job must not change the stored copy
+	got.Status = StatusFailed
+	again, _ := store.Get(ctx, job.ID)
+	if again.Status != StatusQueued {
+		t.Fatalf("store returned shared job, status %s", again.Status)
+	}
+
+	got.Progress = Progress{PagesDone: 1, PagesTotal: 2, CompletedPages: []int{2}}
+	if err := store.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	updated, _ := store.Get(ctx, job.ID)
+	if updated.Status != StatusFailed || updated.Progress.CompletedPages[0] != 2 {
+		t.Fatalf("update not persisted: %+v", updated)
+	}
+
+	jobs, err := store.List(ctx)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("list: %v %+v", err, jobs)
+	}
+
+	if err := store.Delete(ctx, job.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := store.Get(ctx, job.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.Update(ctx, job); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound updating deleted job, got %v", err)
+	}
+	if err := store.Delete(ctx, job.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func newTestJob(t *testing.T) *Job {
+	t.Helper()
+	id, err := newID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Job{
+		ID:        id,
+		Status:    StatusQueued,
+		Filename:  "doc.pdf",
+		Options:   ocr.Options{Language: "eng"},
+		CreatedAt: time.Now().UTC(),
+	}
+}
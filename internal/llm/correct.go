@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrRejected is returned when a correction changes the text too much to be trusted.
+var ErrRejected = errors.New("llm correction rejected")
+
+// correctionPrompt asks for a minimal edit of the page so the model fixes recognition
+// errors without summarizing, translating or completing the text.
+const correctionPrompt = `You correct text produced by OCR. Fix misrecognized characters, broken words, wrong spacing and spelling errors caused by OCR.
+Do not translate, summarize, reorder, complete or comment on the text. Keep names, numbers, dates and amounts exactly as written unless a character was clearly misrecognized.
+%sReply with the corrected text only.
+
+OCR text:
+%s`
+
+// Corrector fixes OCR errors in page text with a language model.
+type Corrector struct {
+	Generator Generator
+
+	// MaxChange bounds how much the corrected text may differ in length from the
+	// original, as a fraction of its length (default: 0.3)
+	MaxChange float64
+}
+
+// NewCorrector returns a Corrector using g.
+func NewCorrector(g Generator) *Corrector {
+	return &Corrector{Generator: g, MaxChange: 0.3}
+}
+
+// Correct returns text with OCR errors fixed. language is the "+" joined tesseract
+// language list the text was recognized with and helps the model pick a spelling.
+// Answers that are empty or change the length by more than MaxChange fail with ErrRejected.
+func (c *Corrector) Correct(ctx context.Context, text, language string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+
+	hint := ""
+	if language != "" {
+		hint = fmt.Sprintf("The text is written in these Tesseract languages: %s.\n", language)
+	}
+	answer, err := c.Generator.Generate(ctx, fmt.Sprintf(correctionPrompt, hint, text))
+	if err != nil {
+		return "", err
+	}
+
+	corrected := strings.TrimSpace(answer)
+	if corrected == "" {
+		return "", fmt.Errorf("%w: empty answer", ErrRejected)
+	}
+	maxChange := c.MaxChange
+	if maxChange <= 0 {
+		maxChange = 0.3
+	}
+	before, after := utf8.RuneCountInString(text), utf8.RuneCountInString(corrected)
+	if diff := float64(after - before); diff > maxChange*float64(before) || -diff > maxChange*float64(before) {
+		return "", fmt.Errorf("%w: length changed from %d to %d characters", ErrRejected, before, after)
+	}
+	return corrected, nil
+}
@@ -0,0 +1,99 @@
+// Package llm talks to a local large language model server and uses it to clean up OCR text.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Generator completes a prompt with a language model.
+type Generator interface {
+	Generate(ctx context.Context, prompt string) (string, error)
+}
+
+// ErrUnavailable is returned when the model server cannot be reached or rejects the request.
+var ErrUnavailable = errors.New("llm unavailable")
+
+// Ollama generates completions with the /api/generate endpoint of an Ollama server.
+type Ollama struct {
+	URL    string // Base URL such as http://localhost:11434
+	Model  string
+	Format string // "json" constrains answers to JSON; empty for free text
+	Client *http.Client
+}
+
+// NewOllama returns an Ollama client for model at baseURL, with requests bounded by timeout.
+func NewOllama(baseURL, model string, timeout time.Duration) *Ollama {
+	return &Ollama{
+		URL:    baseURL,
+		Model:  model,
+		Client: &http.Client{Timeout: timeout},
+	}
+}
+
+type generateRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  bool           `json:"stream"`
+	Format  string         `json:"format,omitempty"`
+	Options map[string]any `json:"options,omitempty"`
+}
+
+type generateResponse struct {
+	Response string `json:"response"`
+	Error    string `json:"error"`
+}
+
+// Generate sends prompt to the model and returns its complete response.
+// Sampling is deterministic so the same page is corrected the same way every time.
+func (o *Ollama) Generate(ctx context.Context, prompt string) (string, error) {
+	endpoint, err := url.JoinPath(o.URL, "api", "generate")
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid url: %v", ErrUnavailable, err)
+	}
+	body, err := json.Marshal(generateRequest{
+		Model:   o.Model,
+		Prompt:  prompt,
+		Format:  o.Format,
+		Options: map[string]any{"temperature": 0},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var result generateResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&result); err != nil {
+		return "", fmt.Errorf("%w: decode response (status %d): %v", ErrUnavailable, resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: status %d: %s", ErrUnavailable, resp.StatusCode, strings.TrimSpace(result.Error))
+	}
+	return result.Response, nil
+}
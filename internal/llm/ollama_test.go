@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOllama_Generate(t *testing.T) {
+	var got generateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/generate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(map[string]any{"response": "fixed", "done": true})
+	}))
+	defer server.Close()
+
+	answer, err := NewOllama(server.URL+"/", "llama3", time.Second).Generate(context.Background(), "fix this")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer != "fixed" {
+		t.Fatalf("unexpected answer %q", answer)
+	}
+	if got.Model != "llama3" || got.Prompt != "fix this" || got.Stream || got.Options["temperature"] != 0.0 {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+}
+
+func TestOllama_GenerateErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": `model "llama3" not found`})
+	}))
+	defer server.Close()
+
+	_, err := NewOllama(server.URL, "llama3", time.Second).Generate(context.Background(), "x")
+	if !errors.Is(err, ErrUnavailable) || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected ErrUnavailable with the server error, got %v", err)
+	}
+
+	server.Close()
+	if _, err := NewOllama(server.URL, "llama3", time.Second).Generate(context.Background(), "x"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable for a closed server, got %v", err)
+	}
+}
+
+type stubGenerator struct {
+	answer string
+	prompt string
+}
+
+func (s *stubGenerator) Generate(ctx context.Context, prompt string) (string, error) {
+	s.prompt = prompt
+	return s.answer, nil
+}
+
+func TestCorrector_Correct(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		want   string
+		err    error
+	}{
+		{"corrected", "  Invoice total: 120.00\n", "Invoice total: 120.00", nil},
+		{"empty", "\n", "", ErrRejected},
+		{"too long", "Invoice total: 120.00, and here is a summary of the whole document", "", ErrRejected},
+		{"too short", "Invoice", "", ErrRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := &stubGenerator{answer: tt.answer}
+			got, err := NewCorrector(gen).Correct(context.Background(), "Inv0ice t0tal: l20.00", "eng+ind")
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("expected %q %v, got %q %v", tt.want, tt.err, got, err)
+			}
+			if !strings.Contains(gen.prompt, "Inv0ice t0tal: l20.00") || !strings.Contains(gen.prompt, "eng+ind") {
+				t.Fatalf("unexpected prompt %q", gen.prompt)
+			}
+		})
+	}
+}
+
+func TestCorrector_SkipsBlankText(t *testing.T) {
+	gen := &stubGenerator{answer: "made up"}
+	if got, err := NewCorrector(gen).Correct(context.Background(), "  \n", "eng"); got != "  \n" || err != nil || gen.prompt != "" {
+		t.Fatalf("expected blank text to be returned as is, got %q %v", got, err)
+	}
+}
@@ -0,0 +1,32 @@
+package metrics
+
+// Default holds the service metrics below and is served at /metrics.
+var Default = NewRegistry()
+
+// Service metrics, updated by the handler, service and OCR layers.
+var (
+	// HTTPRequests counts finished HTTP requests by method, route pattern and status code.
+	HTTPRequests = Default.NewCounter("ocr_http_requests_total",
+		"HTTP requests by method, route and status code.", "method", "route", "status")
+
+	// UploadSize observes the size of uploaded files by route pattern.
+	UploadSize = Default.NewHistogram("ocr_upload_size_bytes",
+		"Size of uploaded files in bytes by route.",
+		[]float64{64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20}, "route")
+
+	// JobsInFlight is the number of OCR jobs, synchronous or queued, being processed.
+	JobsInFlight = Default.NewGauge("ocr_jobs_in_flight",
+		"OCR jobs currently being processed.")
+
+	// PagesProcessed counts pages by source: text_layer or ocr.
+	PagesProcessed = Default.NewCounter("ocr_pages_processed_total",
+		"Pages processed by source (text_layer or ocr).", "source")
+
+	// PageOCRDuration observes how long OCR of a single page took.
+	PageOCRDuration = Default.NewHistogram("ocr_page_ocr_duration_seconds",
+		"Time spent OCRing a single page.", DefaultBuckets)
+
+	// OCRmyPDFFailures counts failed ocrmypdf runs by reason.
+	OCRmyPDFFailures = Default.NewCounter("ocr_ocrmypdf_failures_total",
+		"Failed ocrmypdf runs by reason.", "reason")
+)
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, r *Registry) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatalf("unexpected content type: %s", ct)
+	}
+	return w.Body.String()
+}
+
+func TestRegistry_Counter(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests.", "method", "status")
+	requests.Inc("POST", "200")
+	requests.Add(2, "GET", "200")
+	requests.Inc("POST", "200")
+	requests.Add(-1, "POST", "200") // ignored, counters only go up
+
+	expected := `# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{method="GET",status="200"} 2
+requests_total{method="POST",status="200"} 2
+`
+	if got := scrape(t, r); got != expected {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+	if got := requests.Value("POST", "200"); got != 2 {
+		t.Fatalf("expected 2, got %v", got)
+	}
+}
+
+func TestRegistry_UnlabelledZero(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("events_total", "Events.")
+	g := r.NewGauge("in_flight", "In flight.")
+	g.Inc()
+	g.Inc()
+	g.Dec()
+
+	out := scrape(t, r)
+	if !strings.Contains(out, "\nevents_total 0\n") || !strings.Contains(out, "\nin_flight 1\n") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
+
+func TestRegistry_Histogram(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("duration_seconds", "Duration.", []float64{1, 0.5}, "source")
+	h.Observe(0.2, "ocr")
+	h.Observe(0.7, "ocr")
+	h.Observe(3, "ocr")
+
+	expected := `# HELP duration_seconds Duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{source="ocr",le="0.5"} 1
+duration_seconds_bucket{source="ocr",le="1"} 2
+duration_seconds_bucket{source="ocr",le="+Inf"} 3
+duration_seconds_sum{source="ocr"} 3.9
+duration_seconds_count{source="ocr"} 3
+`
+	if got := scrape(t, r); got != expected {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+	if got := h.Count("ocr"); got != 3 {
+		t.Fatalf("expected 3 observations, got %d", got)
+	}
+}
+
+func TestRegistry_EscapesLabels(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("paths_total", "Paths\nseen.", "path").Inc("a\"b\\c\n")
+
+	out := scrape(t, r)
+	if !strings.Contains(out, `# HELP paths_total Paths\nseen.`) || !strings.Contains(out, `paths_total{path="a\"b\\c\n"} 1`) {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
+
+func TestCounter_LabelMismatchPanics(t *testing.T) {
+	c := NewRegistry().NewCounter("x_total", "X.", "a")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for missing label value")
+		}
+	}()
+	c.Inc()
+}
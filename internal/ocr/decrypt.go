@@ -0,0 +1,46 @@
+package ocr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var (
+	// ErrPasswordRequired is returned when a PDF is encrypted and no password was given.
+	ErrPasswordRequired = errors.New("pdf is encrypted and needs a password")
+	// ErrWrongPassword is returned when the password given for an encrypted PDF does not open it.
+	ErrWrongPassword = errors.New("wrong pdf password")
+)
+
+// DecryptPDF writes a decrypted copy of the PDF at inPath to outPath using password as
+// either the user or the owner password. It returns false without writing anything when
+// the PDF is not encrypted.
+func DecryptPDF(inPath, outPath, password string) (bool, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = password
+	conf.OwnerPW = password
+
+	err := api.DecryptFile(inPath, outPath, conf)
+	switch {
+	case err == nil:
+		return true, nil
+	case isNotEncrypted(err):
+		return false, nil
+	case errors.Is(err, pdfcpu.ErrWrongPassword) && password == "":
+		return false, ErrPasswordRequired
+	case errors.Is(err, pdfcpu.ErrWrongPassword):
+		return false, ErrWrongPassword
+	default:
+		return false, fmt.Errorf("%w: decrypt: %w", ErrInvalidPDF, err)
+	}
+}
+
+// isNotEncrypted reports whether a decryption error only means the PDF was not encrypted.
+func isNotEncrypted(err error) bool {
+	return strings.Contains(err.Error(), "this file is not encrypted")
+}
@@ -0,0 +1,78 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func TestDecryptPDF(t *testing.T) {
+	dir := t.TempDir()
+	plain := writeTestPDF(t, dir, 2)
+	encrypted := writeEncryptedPDF(t, plain, "secret")
+
+	tests := []struct {
+		name     string
+		input    string
+		password string
+		ok       bool
+		err      error
+	}{
+		{"not encrypted", plain, "", false, nil},
+		{"correct password", encrypted, "secret", true, nil},
+		{"missing password", encrypted, "", false, ErrPasswordRequired},
+		{"wrong password", encrypted, "guess", false, ErrWrongPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out.pdf")
+			ok, err := DecryptPDF(tt.input, out, tt.password)
+			if ok != tt.ok || !errors.Is(err, tt.err) {
+				t.Fatalf("got ok=%v err=%v, want ok=%v err=%v", ok, err, tt.ok, tt.err)
+			}
+			if ok {
+				if count, err := api.PageCountFile(out); err != nil || count != 2 {
+					t.Fatalf("expected a readable 2 page pdf, got %d pages: %v", count, err)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractText_EncryptedPDF(t *testing.T) {
+	dir := t.TempDir()
+	encrypted := writeEncryptedPDF(t, writeTestPDF(t, dir, 2), "secret")
+
+	p := &Processor{Extractor: &stubExtractor{}, Recognizer: &stubRecognizer{}}
+
+	if _, err := p.ExtractText(context.Background(), encrypted, Options{}); !errors.Is(err, ErrPasswordRequired) {
+		t.Fatalf("expected ErrPasswordRequired, got %v", err)
+	}
+	if _, err := p.ExtractText(context.Background(), encrypted, Options{Password: "nope"}); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+
+	pages, err := p.ExtractText(context.Background(), encrypted, Options{Password: "secret", Pages: "2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pages) != 1 || pages[0].Page != 2 || pages[0].Content != "recognized" {
+		t.Fatalf("unexpected pages: %+v", pages)
+	}
+}
+
+// writeEncryptedPDF writes an AES encrypted copy of the PDF at path that opens with password.
+func writeEncryptedPDF(t *testing.T, path, password string) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "encrypted.pdf")
+	conf := model.NewAESConfiguration(password, password+"-owner", 256)
+	if err := api.EncryptFile(path, out, conf); err != nil {
+		t.Fatalf("encrypt pdf: %v", err)
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// LanguageAuto as Options.Language picks the languages of each page from its detected script.
+const LanguageAuto = "auto"
+
+// DefaultAutoLanguages are the candidate languages for LanguageAuto when Processor.AutoLanguages is empty.
+var DefaultAutoLanguages = []string{"eng", "ind", "chi_sim"}
+
+// ScriptDetector reports the dominant writing script of a single page PDF, using
+// Tesseract OSD script names such as "Latin" or "Han".
+type ScriptDetector interface {
+	DetectScript(ctx context.Context, pagePath string) (string, error)
+}
+
+// errNoScript is returned when OSD output has no script, usually because the page has too little text.
+var errNoScript = errors.New("no script detected")
+
+// scriptLanguages maps Tesseract OSD script names to the language packs written in them.
+var scriptLanguages = map[string][]string{
+	"Latin":      {"eng", "ind", "msa", "fra", "deu", "spa", "ita", "por", "nld", "vie", "tgl"},
+	"Han":        {"chi_sim", "chi_tra"},
+	"Japanese":   {"jpn"},
+	"Korean":     {"kor"},
+	"Hangul":     {"kor"},
+	"Cyrillic":   {"rus", "ukr", "bul", "srp"},
+	"Arabic":     {"ara", "fas", "urd"},
+	"Greek":      {"ell"},
+	"Hebrew":     {"heb"},
+	"Devanagari": {"hin", "mar", "nep"},
+	"Thai":       {"tha"},
+}
+
+// languagesForScript returns the candidates written in script, or every candidate
+// when the script is unknown or none of them match.
+func languagesForScript(script string, candidates []string) []string {
+	written := make(map[string]bool)
+	for _, code := range scriptLanguages[script] {
+		written[code] = true
+	}
+
+	var matched []string
+	for _, code := range candidates {
+		if written[code] {
+			matched = append(matched, code)
+		}
+	}
+	if len(matched) == 0 {
+		return candidates
+	}
+	return matched
+}
+
+// parseOSD reads the script from Tesseract OSD (--psm 0) output.
+func parseOSD(out []byte) (string, error) {
+	for _, line := range strings.Split(normalizeNewlines(string(out)), "\n") {
+		if script, ok := strings.CutPrefix(line, "Script:"); ok {
+			if script = strings.TrimSpace(script); script != "" {
+				return script, nil
+			}
+		}
+	}
+	return "", errNoScript
+}
@@ -0,0 +1,146 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubDetector reports the script configured for a page path suffix, or err.
+type stubDetector struct {
+	mu      sync.Mutex
+	scripts map[string]string
+	err     error
+	calls   int
+}
+
+func (s *stubDetector) DetectScript(ctx context.Context, pagePath string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	for suffix, script := range s.scripts {
+		if strings.HasSuffix(pagePath, suffix) {
+			return script, nil
+		}
+	}
+	return "", errNoScript
+}
+
+func TestParseOSD(t *testing.T) {
+	out := []byte("Page number: 0\r\nOrientation in degrees: 0\r\nRotate: 0\r\nOrientation confidence: 12.5\r\nScript: Han\r\nScript confidence: 3.2\r\n")
+	script, err := parseOSD(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if script != "Han" {
+		t.Fatalf("expected Han, got %q", script)
+	}
+
+	if _, err := parseOSD([]byte("Too few characters. Skipping this page\n")); !errors.Is(err, errNoScript) {
+		t.Fatalf("expected errNoScript, got %v", err)
+	}
+}
+
+func TestLanguagesForScript(t *testing.T) {
+	candidates := []string{"eng", "ind", "chi_sim"}
+	tests := []struct {
+		script   string
+		expected []string
+	}{
+		{"Latin", []string{"eng", "ind"}},
+		{"Han", []string{"chi_sim"}},
+		{"Cyrillic", candidates},
+		{"Klingon", candidates},
+	}
+
+	for _, tt := range tests {
+		if got := languagesForScript(tt.script, candidates); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("languagesForScript(%q) = %v, want %v", tt.script, got, tt.expected)
+		}
+	}
+}
+
+func TestTesseract_DetectScript(t *testing.T) {
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "tesseract.args")
+
+	rasterizer := writeScript(t, dir, "fake-pdftoppm", `for arg in "$@"; do prefix="$arg"; done
+echo "png" > "$prefix.png"`)
+	binary := writeScript(t, dir, "fake-tesseract", `echo "$@" > `+argsLog+`
+printf 'Orientation in degrees: 0\nScript: Latin\nScript confidence: 8.1\n'`)
+
+	det := &Tesseract{Binary: binary, Rasterizer: rasterizer, Timeout: 10 * time.Second}
+	script, err := det.DetectScript(context.Background(), filepath.Join(dir, "page.pdf"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if script != "Latin" {
+		t.Fatalf("expected Latin, got %q", script)
+	}
+
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if !strings.Contains(string(args), "stdout -l osd") || !strings.Contains(string(args), "--psm 0") {
+		t.Fatalf("unexpected tesseract args: %s", args)
+	}
+}
+
+func TestExtractText_AutoLanguage(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 3)
+
+	rec := &stubRecognizer{}
+	det := &stubDetector{scripts: map[string]string{"page_0001.pdf": "Latin", "page_0002.pdf": "Han"}}
+	p := &Processor{Recognizer: rec, ScriptDetector: det, AutoLanguages: []string{"eng", "ind", "chi_sim"}}
+
+	pages, err := p.ExtractText(context.Background(), pdfPath, Options{Language: LanguageAuto, ForceOCR: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct{ language, script string }{
+		{"eng+ind", "Latin"},
+		{"chi_sim", "Han"},
+		{"eng+ind+chi_sim", ""}, // detection failed, all candidates
+	}
+	for i, want := range expected {
+		if pages[i].Language != want.language || pages[i].Script != want.script {
+			t.Errorf("page %d: got language %q script %q, want %q %q", i+1, pages[i].Language, pages[i].Script, want.language, want.script)
+		}
+	}
+	for _, lang := range rec.langs {
+		if lang == LanguageAuto {
+			t.Fatalf("recognizer called with %q", lang)
+		}
+	}
+}
+
+func TestExtractText_AutoLanguageSkipsTextLayerPages(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 1)
+
+	det := &stubDetector{}
+	p := &Processor{
+		Extractor:      &stubExtractor{texts: map[string]string{"page_0001.pdf": strings.Repeat("text ", 50)}},
+		Recognizer:     &stubRecognizer{},
+		ScriptDetector: det,
+	}
+
+	if _, err := p.ExtractText(context.Background(), pdfPath, Options{Language: LanguageAuto, TextThreshold: 10}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if det.calls != 0 {
+		t.Fatalf("expected no script detection for text layer pages, got %d", det.calls)
+	}
+}
@@ -0,0 +1,73 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Supported engine names.
+const (
+	EngineOCRmyPDF  = "ocrmypdf"
+	EngineTesseract = "tesseract"
+)
+
+// TextExtractor reads the existing text layer of a single page PDF.
+type TextExtractor interface {
+	ExtractPageText(ctx context.Context, pagePath string) (string, error)
+}
+
+// Recognizer runs OCR on a single page PDF and returns the recognized text.
+type Recognizer interface {
+	Recognize(ctx context.Context, pagePath string, opts Options) (string, error)
+}
+
+// PDFRecognizer is implemented by recognizers that can also write the page back
+// as a PDF with an invisible OCR text layer, used for Options.SearchablePDF.
+type PDFRecognizer interface {
+	RecognizePDF(ctx context.Context, pagePath, outputPath string, opts Options) (string, error)
+}
+
+// NewRecognizer returns the recognizer for the named engine.
+// An empty name selects OCRmyPDF.
+func NewRecognizer(engine string, timeout time.Duration) (Recognizer, error) {
+	switch engine {
+	case "", EngineOCRmyPDF:
+		return &OCRmyPDF{Timeout: timeout}, nil
+	case EngineTesseract:
+		return &Tesseract{Timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown ocr engine %q", engine)
+	}
+}
+
+// NewProcessorWithEngine returns a Processor with sane defaults using the named engine.
+func NewProcessorWithEngine(engine string) (*Processor, error) {
+	p := NewProcessor()
+	rec, err := NewRecognizer(engine, p.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	p.Recognizer = rec
+	return p, nil
+}
+
+// EnsureEngine checks whether the binaries required by the named engine are available on PATH.
+// Every engine needs tesseract and pdftoppm, which lang=auto and layout output always use.
+func EnsureEngine(engine string) error {
+	switch engine {
+	case "", EngineOCRmyPDF:
+		if err := EnsureBinary(""); err != nil {
+			return err
+		}
+	case EngineTesseract:
+	default:
+		return fmt.Errorf("unknown ocr engine %q", engine)
+	}
+	for _, binary := range []string{"tesseract", "pdftoppm"} {
+		if _, err := ResolveBinary(binary); err != nil {
+			return fmt.Errorf("%s binary not found: %w", binary, err)
+		}
+	}
+	return nil
+}
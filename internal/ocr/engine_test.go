@@ -0,0 +1,302 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"app/pkg"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+type stubExtractor struct {
+	texts map[string]string
+}
+
+func (s *stubExtractor) ExtractPageText(ctx context.Context, pagePath string) (string, error) {
+	for suffix, text := range s.texts {
+		if strings.HasSuffix(pagePath, suffix) {
+			return text, nil
+		}
+	}
+	return "", nil
+}
+
+type stubRecognizer struct {
+	mu    sync.Mutex
+	calls []string
+	langs []string
+	err   error
+}
+
+func (s *stubRecognizer) Recognize(ctx context.Context, pagePath string, opts Options) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, pagePath)
+	s.langs = append(s.langs, opts.Language)
+	if s.err != nil {
+		return "", s.err
+	}
+	return "recognized", nil
+}
+
+func TestNewRecognizer(t *testing.T) {
+	tests := []struct {
+		engine   string
+		expected string
+	}{
+		{"", "*ocr.OCRmyPDF"},
+		{EngineOCRmyPDF, "*ocr.OCRmyPDF"},
+		{EngineTesseract, "*ocr.Tesseract"},
+	}
+
+	for _, tt := range tests {
+		rec, err := NewRecognizer(tt.engine, 0)
+		if err != nil {
+			t.Fatalf("NewRecognizer(%q) unexpected error: %v", tt.engine, err)
+		}
+		if got := fmt.Sprintf("%T", rec); got != tt.expected {
+			t.Errorf("NewRecognizer(%q) = %s, want %s", tt.engine, got, tt.expected)
+		}
+	}
+}
+
+func TestNewRecognizer_Unknown(t *testing.T) {
+	if _, err := NewRecognizer("abbyy", 0); err == nil {
+		t.Fatal("expected error for unknown engine")
+	}
+	if _, err := NewProcessorWithEngine("abbyy"); err == nil {
+		t.Fatal("expected error for unknown engine")
+	}
+	if err := EnsureEngine("abbyy"); err == nil {
+		t.Fatal("expected error for unknown engine")
+	}
+}
+
+func TestEnsureEngine_RequiresTesseractTools(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	for _, binary := range []string{"ocrmypdf", "tesseract"} {
+		if err := os.WriteFile(filepath.Join(dir, binary), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatalf("write %s: %v", binary, err)
+		}
+	}
+
+	for _, engine := range []string{EngineOCRmyPDF, EngineTesseract} {
+		if err := EnsureEngine(engine); err == nil || !strings.Contains(err.Error(), "pdftoppm") {
+			t.Errorf("EnsureEngine(%q) = %v, want missing pdftoppm", engine, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "pdftoppm"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write pdftoppm: %v", err)
+	}
+	for _, engine := range []string{EngineOCRmyPDF, EngineTesseract} {
+		if err := EnsureEngine(engine); err != nil {
+			t.Errorf("EnsureEngine(%q) unexpected error: %v", engine, err)
+		}
+	}
+}
+
+func TestProcessor_DefaultEngine(t *testing.T) {
+	p := NewProcessor()
+	if _, ok := p.extractor().(*Pdftotext); !ok {
+		t.Errorf("expected pdftotext extractor by default, got %T", p.extractor())
+	}
+	rec, ok := p.recognizer().(*OCRmyPDF)
+	if !ok {
+		t.Fatalf("expected ocrmypdf recognizer by default, got %T", p.recognizer())
+	}
+	if rec.Binary != p.Binary || rec.Timeout != p.Timeout {
+		t.Errorf("expected default recognizer to inherit processor settings, got %+v", rec)
+	}
+}
+
+func TestExtractText_UsesComposedEngine(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 3)
+
+	extractor := &stubExtractor{texts: map[string]string{
+		"page_0002.pdf": strings.Repeat("existing text ", 20),
+	}}
+	recognizer := &stubRecognizer{}
+	p := &Processor{Extractor: extractor, Recognizer: recognizer, Concurrency: 2}
+
+	pages, err := p.ExtractText(context.Background(), pdfPath, Options{Language: "eng"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pages) != 3 {
+		t.Fatalf("expected 3 pages, got %+v", pages)
+	}
+	if !strings.HasPrefix(pages[1].Content, "existing text") {
+		t.Errorf("expected page 2 from text layer, got %q", pages[1].Content)
+	}
+	if pages[0].Content != "recognized" || pages[2].Content != "recognized" {
+		t.Errorf("expected pages 1 and 3 from recognizer, got %+v", pages)
+	}
+	if len(recognizer.calls) != 2 {
+		t.Errorf("expected 2 recognizer calls, got %d", len(recognizer.calls))
+	}
+	for _, lang := range recognizer.langs {
+		if lang != "eng" {
+			t.Errorf("expected language to pass through, got %q", lang)
+		}
+	}
+}
+
+func TestExtractText_PageMetadata(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 2)
+
+	extractor := &stubExtractor{texts: map[string]string{
+		"page_0002.pdf": strings.Repeat("x", 200),
+	}}
+	p := &Processor{Extractor: extractor, Recognizer: &stubRecognizer{}}
+
+	pages, err := p.ExtractText(context.Background(), pdfPath, Options{Language: "ind", RemoveWatermark: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %+v", pages)
+	}
+
+	ocrPage, textPage := pages[0], pages[1]
+	if ocrPage.Source != SourceOCR || ocrPage.Language != "ind" || ocrPage.Chars != len("recognized") {
+		t.Errorf("unexpected ocr page metadata: %+v", ocrPage)
+	}
+	if textPage.Source != SourceTextLayer || textPage.Language != "" || textPage.Chars != 200 || textPage.OCRDurationMS != 0 {
+		t.Errorf("unexpected text layer page metadata: %+v", textPage)
+	}
+	// The blank test pages carry no watermark
+	for _, page := range pages {
+		if page.Watermark != WatermarkNone {
+			t.Errorf("page %d: expected watermark outcome %q, got %q", page.Page, WatermarkNone, page.Watermark)
+		}
+	}
+
+	forced, err := p.ExtractText(context.Background(), pdfPath, Options{ForceOCR: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, page := range forced {
+		if page.Source != SourceOCR || page.Watermark != WatermarkSkipped {
+			t.Errorf("page %d: expected forced OCR without watermark removal, got %+v", page.Page, page)
+		}
+	}
+}
+
+func TestExtractText_Normalization(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 1)
+
+	text := "Name     Amount\nCoffee   3.50   \n" + strings.Repeat("x", 200)
+	p := &Processor{Extractor: &stubExtractor{texts: map[string]string{"page_0001.pdf": text}}, Recognizer: &stubRecognizer{}}
+
+	tests := []struct {
+		mode     pkg.Normalization
+		expected string
+	}{
+		{"", "Name Amount Coffee 3.50 " + strings.Repeat("x", 200)},
+		{pkg.NormalizationLines, "Name     Amount\nCoffee   3.50\n" + strings.Repeat("x", 200)},
+	}
+	for _, tt := range tests {
+		pages, err := p.ExtractText(context.Background(), pdfPath, Options{Normalization: tt.mode})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(pages) != 1 || pages[0].Content != tt.expected {
+			t.Errorf("%q: unexpected content %+v", tt.mode, pages)
+		}
+	}
+}
+
+func TestExtractText_RecognizerError(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 1)
+
+	wantErr := errors.New("engine failed")
+	p := &Processor{Extractor: &stubExtractor{}, Recognizer: &stubRecognizer{err: wantErr}}
+
+	_, err := p.ExtractText(context.Background(), pdfPath, Options{})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+}
+
+func TestExtractText_ReportsProgress(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 3)
+
+	var events []Progress
+	p := &Processor{Extractor: &stubExtractor{}, Recognizer: &stubRecognizer{}, Concurrency: 3}
+	_, err := p.ExtractText(context.Background(), pdfPath, Options{
+		OnProgress: func(progress Progress) { events = append(events, progress) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("expected initial event plus one per page, got %+v", events)
+	}
+	if events[0].Done != 0 || events[0].Total != 3 || events[0].Page != nil {
+		t.Errorf("unexpected initial event: %+v", events[0])
+	}
+	seen := map[int]bool{}
+	for i, event := range events[1:] {
+		if event.Done != i+1 || event.Total != 3 || event.Page == nil {
+			t.Fatalf("unexpected page event: %+v", event)
+		}
+		seen[event.Page.Page] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("expected every page reported once, got %v", seen)
+	}
+}
+
+// watermarkExtractor reads a page's watermark as text, as pdftotext would.
+type watermarkExtractor struct{}
+
+func (watermarkExtractor) ExtractPageText(ctx context.Context, pagePath string) (string, error) {
+	text := strings.Repeat("body ", 40)
+	if watermarked, err := api.HasWatermarksFile(pagePath, model.NewDefaultConfiguration()); err == nil && watermarked {
+		text = "DRAFT " + text
+	}
+	return text, nil
+}
+
+func TestProcessPage_RemoveWatermarkSwitch(t *testing.T) {
+	dir := t.TempDir()
+	plain := writeTestPDF(t, dir, 1)
+	pagePath := filepath.Join(dir, "page_0001.pdf")
+	if err := api.AddTextWatermarksFile(plain, pagePath, nil, false, "DRAFT", "points:24", model.NewDefaultConfiguration()); err != nil {
+		t.Fatalf("add watermark: %v", err)
+	}
+	p := &Processor{Extractor: watermarkExtractor{}, Recognizer: &stubRecognizer{}}
+
+	kept, _, err := p.processPage(context.Background(), 1, pagePath, Options{TextThreshold: 150, RemoveWatermark: false})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(kept.Content, "DRAFT") || kept.Watermark != WatermarkSkipped {
+		t.Fatalf("expected remove_watermark=false to keep the watermark text, got %+v", kept)
+	}
+
+	removed, _, err := p.processPage(context.Background(), 1, pagePath, Options{TextThreshold: 150, RemoveWatermark: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(removed.Content, "DRAFT") || removed.Watermark != WatermarkRemoved {
+		t.Fatalf("expected the watermark to be removed, got %+v", removed)
+	}
+}
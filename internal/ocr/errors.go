@@ -0,0 +1,73 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+// Errors returned by Processor.ExtractText and the engines, wrapped with details.
+// Match them with errors.Is.
+var (
+	ErrInvalidPDF          = errors.New("invalid pdf")
+	ErrTooManyPages        = errors.New("too many pages")
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	ErrEngineMissing       = errors.New("ocr engine not installed")
+	ErrEngineTimeout       = errors.New("ocr engine timed out")
+	ErrEngineFailed        = errors.New("ocr engine failed")
+)
+
+// missingLanguageMessages are printed by tesseract and OCRmyPDF when a language pack is not installed.
+var missingLanguageMessages = []string{
+	"Failed loading language",
+	"couldn't load any languages",
+	"does not have language data",
+}
+
+// engineError classifies a failed run of the named binary. ctx is the caller's context and
+// cmdCtx the one the command ran under with its own timeout, so only that timeout counts as
+// an engine timeout; runs stopped because ctx ended return ctx's error. The original error
+// and stderr are kept for the logs.
+func engineError(ctx, cmdCtx context.Context, name string, err error, stderr string) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, exec.ErrNotFound) && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: %w", name, ctxErr)
+	}
+	kind := ErrEngineFailed
+	switch {
+	case errors.Is(err, exec.ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		kind = ErrEngineMissing
+	case errors.Is(cmdCtx.Err(), context.DeadlineExceeded):
+		kind = ErrEngineTimeout
+	case isMissingLanguage(stderr):
+		kind = ErrUnsupportedLanguage
+	}
+	return fmt.Errorf("%w: %s: %w - %s", kind, name, err, strings.TrimSpace(stderr))
+}
+
+func isMissingLanguage(stderr string) bool {
+	for _, msg := range missingLanguageMessages {
+		if strings.Contains(stderr, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// failureReason names the kind of an engineError for metrics. Runs stopped because
+// ctx, the caller's context, ended are reported as cancelled.
+func failureReason(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, ErrEngineMissing):
+		return "missing"
+	case errors.Is(err, ErrEngineTimeout):
+		return "timeout"
+	case ctx.Err() != nil:
+		return "cancelled"
+	case errors.Is(err, ErrUnsupportedLanguage):
+		return "unsupported_language"
+	}
+	return "failed"
+}
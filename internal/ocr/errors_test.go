@@ -0,0 +1,104 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"app/internal/metrics"
+)
+
+func TestOCRmyPDF_ErrorKinds(t *testing.T) {
+	dir := t.TempDir()
+	pagePath := filepath.Join(dir, "page.pdf")
+	if err := os.WriteFile(pagePath, []byte("%PDF-1.4"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		binary   string
+		timeout  time.Duration
+		expected error
+		reason   string
+	}{
+		{"missing binary", filepath.Join(dir, "does-not-exist"), 0, ErrEngineMissing, "missing"},
+		{"timeout", writeScript(t, dir, "slow", "exec sleep 2"), 50 * time.Millisecond, ErrEngineTimeout, "timeout"},
+		{"missing language", writeScript(t, dir, "nolang", `echo "OCR engine does not have language data for the following requested languages: xyz" >&2; exit 1`), 0, ErrUnsupportedLanguage, "unsupported_language"},
+		{"failure", writeScript(t, dir, "broken", `echo "boom" >&2; exit 2`), 0, ErrEngineFailed, "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := metrics.OCRmyPDFFailures.Value(tt.reason)
+			rec := &OCRmyPDF{Binary: tt.binary, Timeout: tt.timeout}
+			_, err := rec.Recognize(context.Background(), pagePath, Options{Language: "xyz"})
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+			if got := metrics.OCRmyPDFFailures.Value(tt.reason) - before; got != 1 {
+				t.Fatalf("expected one %s failure recorded, got %v", tt.reason, got)
+			}
+		})
+	}
+}
+
+func TestOCRmyPDF_CallerDeadlineIsNotEngineTimeout(t *testing.T) {
+	dir := t.TempDir()
+	pagePath := filepath.Join(dir, "page.pdf")
+	if err := os.WriteFile(pagePath, []byte("%PDF-1.4"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The request or job deadline ends the run long before the engine's own timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	before := metrics.OCRmyPDFFailures.Value("timeout")
+	rec := &OCRmyPDF{Binary: writeScript(t, dir, "slow", "exec sleep 2"), Timeout: time.Minute}
+	_, err := rec.Recognize(ctx, pagePath, Options{})
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrEngineTimeout) {
+		t.Fatalf("expected the caller's deadline, got %v", err)
+	}
+	if metrics.OCRmyPDFFailures.Value("timeout") != before {
+		t.Fatal("expected no engine timeout recorded")
+	}
+}
+
+func TestTesseract_MissingLanguage(t *testing.T) {
+	dir := t.TempDir()
+	rasterizer := writeScript(t, dir, "pdftoppm", `for last; do :; done; : > "$last.png"`)
+	binary := writeScript(t, dir, "tesseract", `echo "Failed loading language 'xyz'" >&2; echo "Tesseract couldn't load any languages!" >&2; exit 1`)
+
+	rec := &Tesseract{Binary: binary, Rasterizer: rasterizer}
+	if _, err := rec.Recognize(context.Background(), filepath.Join(dir, "page.pdf"), Options{Language: "xyz"}); !errors.Is(err, ErrUnsupportedLanguage) {
+		t.Fatalf("expected ErrUnsupportedLanguage, got %v", err)
+	}
+}
+
+func TestExtractText_InvalidPDF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	if err := os.WriteFile(path, []byte("not a pdf"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Processor{Extractor: &stubExtractor{}, Recognizer: &stubRecognizer{}}
+	if _, err := p.ExtractText(context.Background(), path, Options{}); !errors.Is(err, ErrInvalidPDF) {
+		t.Fatalf("expected ErrInvalidPDF, got %v", err)
+	}
+}
+
+func TestExtractText_TooManyPages(t *testing.T) {
+	pdfPath := writeTestPDF(t, t.TempDir(), 3)
+	p := &Processor{Extractor: &stubExtractor{}, Recognizer: &stubRecognizer{}, MaxPages: 2}
+
+	if _, err := p.ExtractText(context.Background(), pdfPath, Options{}); !errors.Is(err, ErrTooManyPages) {
+		t.Fatalf("expected ErrTooManyPages, got %v", err)
+	}
+	// A selection within the limit is fine
+	if _, err := p.ExtractText(context.Background(), pdfPath, Options{Pages: "2-3"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
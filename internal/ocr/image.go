@@ -0,0 +1,96 @@
+package ocr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Supported image content types.
+const (
+	ImagePNG  = "image/png"
+	ImageJPEG = "image/jpeg"
+	ImageTIFF = "image/tiff"
+	ImageWebP = "image/webp"
+)
+
+// ErrUnsupportedImage is returned when an upload is not a supported image format.
+var ErrUnsupportedImage = errors.New("unsupported image type")
+
+var (
+	tiffLittleEndian = []byte("II*\x00")
+	tiffBigEndian    = []byte("MM\x00*")
+)
+
+// DetectImageType sniffs the content type from the leading bytes of an image.
+func DetectImageType(data []byte) (string, error) {
+	// net/http does not sniff TIFF, so check its magic numbers first
+	if bytes.HasPrefix(data, tiffLittleEndian) || bytes.HasPrefix(data, tiffBigEndian) {
+		return ImageTIFF, nil
+	}
+
+	switch contentType := http.DetectContentType(data); contentType {
+	case ImagePNG, ImageJPEG, ImageWebP:
+		return contentType, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedImage, contentType)
+	}
+}
+
+// SaveUploadedImage copies the provided reader to a temporary file and returns its sniffed content type.
+func SaveUploadedImage(r io.Reader) (string, string, func(), error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", "", nil, fmt.Errorf("read image header: %w", err)
+	}
+	head = head[:n]
+
+	contentType, err := DetectImageType(head)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	tmpFile, err := os.CreateTemp(TempDir(), "ocr-input-*.img")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("create temp image: %w", err)
+	}
+
+	cleanup := func() {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+	}
+
+	if _, err := io.Copy(tmpFile, io.MultiReader(bytes.NewReader(head), r)); err != nil {
+		cleanup()
+		return "", "", nil, fmt.Errorf("write temp image: %w", err)
+	}
+
+	return tmpFile.Name(), contentType, cleanup, nil
+}
+
+// ImageToPDF wraps an image into a PDF sized to the image, one page per frame for multi-page TIFFs.
+func ImageToPDF(imagePath string) (string, func(), error) {
+	tempDir, err := os.MkdirTemp(TempDir(), "ocr-image-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	pdfPath := filepath.Join(tempDir, "image.pdf")
+	conf := model.NewDefaultConfiguration()
+	if err := api.ImportImagesFile([]string{imagePath}, pdfPath, pdfcpu.DefaultImportConfig(), conf); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("import image: %w", err)
+	}
+
+	return pdfPath, cleanup, nil
+}
@@ -0,0 +1,190 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestDetectImageType(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"png", encodePNG(t), ImagePNG},
+		{"jpeg", encodeJPEG(t), ImageJPEG},
+		{"tiff little endian", encodeTIFF(t, 1), ImageTIFF},
+		{"tiff big endian", []byte("MM\x00*\x00\x00\x00\x08"), ImageTIFF},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), ImageWebP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectImageType(tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("DetectImageType() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectImageType_Unsupported(t *testing.T) {
+	for _, data := range [][]byte{[]byte("%PDF-1.4"), []byte("plain text"), {}} {
+		if _, err := DetectImageType(data); !errors.Is(err, ErrUnsupportedImage) {
+			t.Errorf("DetectImageType(%q) expected ErrUnsupportedImage, got %v", data, err)
+		}
+	}
+}
+
+func TestSaveUploadedImage(t *testing.T) {
+	data := encodePNG(t)
+
+	path, contentType, cleanup, err := SaveUploadedImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != ImagePNG {
+		t.Errorf("expected %s, got %s", ImagePNG, contentType)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read saved image: %v", err)
+	}
+	if !bytes.Equal(saved, data) {
+		t.Error("saved image differs from upload")
+	}
+
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected cleanup to remove %s, got %v", path, err)
+	}
+}
+
+func TestSaveUploadedImage_RejectsNonImage(t *testing.T) {
+	_, _, _, err := SaveUploadedImage(bytes.NewReader([]byte("%PDF-1.4 not an image")))
+	if !errors.Is(err, ErrUnsupportedImage) {
+		t.Fatalf("expected ErrUnsupportedImage, got %v", err)
+	}
+}
+
+func TestImageToPDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		pages int
+	}{
+		{"png", encodePNG(t), 1},
+		{"jpeg", encodeJPEG(t), 1},
+		{"multi-page tiff", encodeTIFF(t, 3), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imagePath, _, cleanupImage, err := SaveUploadedImage(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("save image: %v", err)
+			}
+			defer cleanupImage()
+
+			pdfPath, cleanup, err := ImageToPDF(imagePath)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer cleanup()
+
+			count, err := api.PageCountFile(pdfPath)
+			if err != nil {
+				t.Fatalf("page count: %v", err)
+			}
+			if count != tt.pages {
+				t.Errorf("expected %d pages, got %d", tt.pages, count)
+			}
+		})
+	}
+}
+
+func testImage() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 4)
+	}
+	img.Set(0, 0, color.White)
+	return img
+}
+
+func encodePNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// encodeTIFF writes an uncompressed 8x8 grayscale TIFF with the given number of frames.
+func encodeTIFF(t *testing.T, frames int) []byte {
+	t.Helper()
+	const (
+		width, height = 8, 8
+		entries       = 8
+		ifdSize       = 2 + entries*12 + 4
+	)
+	pixels := testImage().(*image.Gray).Pix
+
+	var buf bytes.Buffer
+	le := binary.LittleEndian
+	buf.WriteString("II")
+	binary.Write(&buf, le, uint16(42))
+	binary.Write(&buf, le, uint32(8))
+
+	for frame := 0; frame < frames; frame++ {
+		ifdOffset := 8 + frame*(ifdSize+len(pixels))
+		stripOffset := uint32(ifdOffset + ifdSize)
+		next := uint32(0)
+		if frame < frames-1 {
+			next = uint32(ifdOffset + ifdSize + len(pixels))
+		}
+
+		binary.Write(&buf, le, uint16(entries))
+		for _, e := range [][3]uint32{
+			{256, 3, width},               // ImageWidth
+			{257, 3, height},              // ImageLength
+			{258, 3, 8},                   // BitsPerSample
+			{259, 3, 1},                   // Compression: none
+			{262, 3, 1},                   // Photometric: BlackIsZero
+			{273, 4, stripOffset},         // StripOffsets
+			{278, 3, height},              // RowsPerStrip
+			{279, 4, uint32(len(pixels))}, // StripByteCounts
+		} {
+			binary.Write(&buf, le, uint16(e[0]))
+			binary.Write(&buf, le, uint16(e[1]))
+			binary.Write(&buf, le, uint32(1))
+			binary.Write(&buf, le, e[2])
+		}
+		binary.Write(&buf, le, next)
+		buf.Write(pixels)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,105 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LanguagePattern matches tesseract language codes joined by "+", as accepted in Options.Language.
+var LanguagePattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\+[A-Za-z0-9_]+)*$`)
+
+// LanguageSet is the set of language packs installed for the OCR engine.
+type LanguageSet struct {
+	codes []string
+	index map[string]bool
+	osd   bool
+}
+
+// NewLanguageSet creates a LanguageSet from language codes. The orientation and script
+// detection pack (osd) is not a language; it is recorded for SupportsAuto and left out.
+func NewLanguageSet(codes []string) *LanguageSet {
+	s := &LanguageSet{index: make(map[string]bool, len(codes))}
+	for _, code := range codes {
+		if code == "osd" {
+			s.osd = true
+			continue
+		}
+		if code == "" || s.index[code] {
+			continue
+		}
+		s.index[code] = true
+		s.codes = append(s.codes, code)
+	}
+	sort.Strings(s.codes)
+	return s
+}
+
+// Codes returns the installed language codes in alphabetical order.
+func (s *LanguageSet) Codes() []string {
+	return append([]string(nil), s.codes...)
+}
+
+// Has reports whether the language pack is installed.
+func (s *LanguageSet) Has(code string) bool {
+	return s.index[code]
+}
+
+// SupportsAuto reports whether the osd pack needed for LanguageAuto is installed along
+// with at least one language to pick from.
+func (s *LanguageSet) SupportsAuto() bool {
+	return s.osd && len(s.codes) > 0
+}
+
+// Validate checks that every language in a "+" joined list such as "eng+ind" is installed.
+func (s *LanguageSet) Validate(lang string) error {
+	var missing []string
+	for _, code := range strings.Split(lang, "+") {
+		if !s.Has(code) {
+			missing = append(missing, code)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedLanguage, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ListLanguages asks tesseract for its installed language packs.
+func ListLanguages(ctx context.Context, binary string) (*LanguageSet, error) {
+	if binary == "" {
+		binary = "tesseract"
+	}
+
+	cmd := exec.CommandContext(ctx, binary, "--list-langs")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, engineError(ctx, ctx, binary, err, stderr.String())
+	}
+
+	// Older tesseract versions print the list to stderr
+	out := stdout.String()
+	if strings.TrimSpace(out) == "" {
+		out = stderr.String()
+	}
+	return NewLanguageSet(parseLanguageList(out)), nil
+}
+
+// parseLanguageList reads the output of tesseract --list-langs.
+func parseLanguageList(out string) []string {
+	var codes []string
+	for _, line := range strings.Split(normalizeNewlines(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "List of available languages") {
+			continue
+		}
+		codes = append(codes, line)
+	}
+	return codes
+}
@@ -0,0 +1,69 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestListLanguages(t *testing.T) {
+	dir := t.TempDir()
+	binary := writeScript(t, dir, "tesseract", `[ "$1" = "--list-langs" ] || exit 2
+echo 'List of available languages in "/usr/share/tesseract-ocr/5/tessdata/" (4):'
+printf 'ind\neng\nosd\nchi_sim\n'`)
+
+	langs, err := ListLanguages(context.Background(), binary)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := langs.Codes(); !reflect.DeepEqual(got, []string{"chi_sim", "eng", "ind"}) {
+		t.Fatalf("unexpected languages: %v", got)
+	}
+	if !langs.SupportsAuto() {
+		t.Fatal("expected osd to enable auto detection")
+	}
+}
+
+func TestListLanguages_Stderr(t *testing.T) {
+	dir := t.TempDir()
+	binary := writeScript(t, dir, "tesseract", `printf 'List of available languages (2):\neng\nosd\n' >&2`)
+
+	langs, err := ListLanguages(context.Background(), binary)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := langs.Codes(); !reflect.DeepEqual(got, []string{"eng"}) {
+		t.Fatalf("unexpected languages: %v", got)
+	}
+}
+
+func TestListLanguages_MissingBinary(t *testing.T) {
+	if _, err := ListLanguages(context.Background(), "/nonexistent/tesseract"); !errors.Is(err, ErrEngineMissing) {
+		t.Fatalf("expected ErrEngineMissing, got %v", err)
+	}
+}
+
+func TestLanguageSet_Validate(t *testing.T) {
+	langs := NewLanguageSet([]string{"eng", "ind", "eng"})
+
+	for _, lang := range []string{"eng", "eng+ind"} {
+		if err := langs.Validate(lang); err != nil {
+			t.Errorf("%q: unexpected error: %v", lang, err)
+		}
+	}
+	for _, lang := range []string{"fra", "eng+chi_sim", ""} {
+		if err := langs.Validate(lang); !errors.Is(err, ErrUnsupportedLanguage) {
+			t.Errorf("%q: expected ErrUnsupportedLanguage, got %v", lang, err)
+		}
+	}
+	if got := langs.Codes(); !reflect.DeepEqual(got, []string{"eng", "ind"}) {
+		t.Errorf("unexpected codes: %v", got)
+	}
+	if langs.SupportsAuto() {
+		t.Error("expected no auto detection without osd")
+	}
+	if NewLanguageSet([]string{"osd"}).SupportsAuto() {
+		t.Error("expected no auto detection without languages to pick from")
+	}
+}
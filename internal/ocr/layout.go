@@ -0,0 +1,236 @@
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BBox is an axis-aligned box with the origin at the top-left corner of the page.
+type BBox struct {
+	X0 float64 `json:"x0"`
+	Y0 float64 `json:"y0"`
+	X1 float64 `json:"x1"`
+	Y1 float64 `json:"y1"`
+}
+
+// Word is a single recognized word with its position and confidence (0-100).
+type Word struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	BBox       BBox    `json:"bbox"`    // PDF points
+	PixelBBox  BBox    `json:"bbox_px"` // Pixels at the rasterization DPI
+}
+
+// Line is a line of words; its confidence is the mean of its words.
+type Line struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	BBox       BBox    `json:"bbox"`
+	PixelBBox  BBox    `json:"bbox_px"`
+	Words      []Word  `json:"words"`
+}
+
+// LayoutRecognizer runs OCR on a single page PDF and returns the text with word positions.
+type LayoutRecognizer interface {
+	RecognizeLayout(ctx context.Context, pagePath string, opts Options) (string, []Line, error)
+}
+
+// LayoutExtractor reads the existing text layer of a single page PDF with word positions.
+type LayoutExtractor interface {
+	ExtractPageLayout(ctx context.Context, pagePath string) (string, []Line, error)
+}
+
+// union grows b to include o.
+func (b BBox) union(o BBox) BBox {
+	return BBox{
+		X0: min(b.X0, o.X0),
+		Y0: min(b.Y0, o.Y0),
+		X1: max(b.X1, o.X1),
+		Y1: max(b.Y1, o.Y1),
+	}
+}
+
+// scale multiplies every coordinate by factor.
+func (b BBox) scale(factor float64) BBox {
+	return BBox{X0: b.X0 * factor, Y0: b.Y0 * factor, X1: b.X1 * factor, Y1: b.Y1 * factor}
+}
+
+// newLine builds a line from its words, deriving text, bounding boxes and mean confidence.
+func newLine(words []Word) Line {
+	line := Line{Words: words, BBox: words[0].BBox, PixelBBox: words[0].PixelBBox}
+	texts := make([]string, len(words))
+	var total float64
+	for i, w := range words {
+		texts[i] = w.Text
+		total += w.Confidence
+		line.BBox = line.BBox.union(w.BBox)
+		line.PixelBBox = line.PixelBBox.union(w.PixelBBox)
+	}
+	line.Text = strings.Join(texts, " ")
+	line.Confidence = total / float64(len(words))
+	return line
+}
+
+// linesText joins line texts with newlines.
+func linesText(lines []Line) string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// parseTSV parses Tesseract TSV output into lines of words.
+// Pixel coordinates are converted to PDF points using the rasterization dpi.
+func parseTSV(data []byte, dpi int) ([]Line, error) {
+	const (
+		colLevel = iota
+		colPage
+		colBlock
+		colPar
+		colLine
+		colWord
+		colLeft
+		colTop
+		colWidth
+		colHeight
+		colConf
+		colText
+		columns
+	)
+	const wordLevel = "5"
+
+	toPoints := 72 / float64(dpi)
+
+	var (
+		lines   []Line
+		words   []Word
+		lineKey string
+	)
+	flush := func() {
+		if len(words) > 0 {
+			lines = append(lines, newLine(words))
+			words = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for row := 0; scanner.Scan(); row++ {
+		fields := strings.Split(strings.TrimRight(scanner.Text(), "\r"), "\t")
+		if row == 0 && len(fields) > 0 && fields[0] == "level" {
+			continue
+		}
+		if len(fields) < columns-1 || fields[colLevel] != wordLevel {
+			continue
+		}
+		text := ""
+		if len(fields) >= columns {
+			text = strings.TrimSpace(fields[colText])
+		}
+		if text == "" {
+			continue
+		}
+
+		var nums [4]float64
+		for i, col := range []int{colLeft, colTop, colWidth, colHeight} {
+			n, err := strconv.ParseFloat(fields[col], 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse tsv row %d: %w", row+1, err)
+			}
+			nums[i] = n
+		}
+		conf, err := strconv.ParseFloat(fields[colConf], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse tsv row %d: %w", row+1, err)
+		}
+
+		key := strings.Join(fields[colPage:colWord], ".")
+		if key != lineKey {
+			flush()
+			lineKey = key
+		}
+
+		px := BBox{X0: nums[0], Y0: nums[1], X1: nums[0] + nums[2], Y1: nums[1] + nums[3]}
+		words = append(words, Word{
+			Text:       text,
+			Confidence: conf,
+			BBox:       px.scale(toPoints),
+			PixelBBox:  px,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read tsv: %w", err)
+	}
+	flush()
+
+	return lines, nil
+}
+
+// bboxDocument mirrors the XHTML produced by pdftotext -bbox-layout.
+type bboxDocument struct {
+	Pages []struct {
+		Flows []struct {
+			Blocks []struct {
+				Lines []struct {
+					Words []struct {
+						XMin float64 `xml:"xMin,attr"`
+						YMin float64 `xml:"yMin,attr"`
+						XMax float64 `xml:"xMax,attr"`
+						YMax float64 `xml:"yMax,attr"`
+						Text string  `xml:",chardata"`
+					} `xml:"word"`
+				} `xml:"line"`
+			} `xml:"block"`
+		} `xml:"flow"`
+	} `xml:"body>doc>page"`
+}
+
+// parseBBoxLayout parses pdftotext -bbox-layout output into lines of words.
+// Text-layer words carry full confidence; pixel coordinates are derived for the given dpi.
+func parseBBoxLayout(data []byte, dpi int) ([]Line, error) {
+	var doc bboxDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse bbox layout: %w", err)
+	}
+
+	toPixels := float64(dpi) / 72
+
+	var lines []Line
+	for _, page := range doc.Pages {
+		for _, flow := range page.Flows {
+			for _, block := range flow.Blocks {
+				for _, l := range block.Lines {
+					var words []Word
+					for _, w := range l.Words {
+						text := strings.TrimSpace(w.Text)
+						if text == "" {
+							continue
+						}
+						pt := BBox{X0: w.XMin, Y0: w.YMin, X1: w.XMax, Y1: w.YMax}
+						words = append(words, Word{
+							Text:       text,
+							Confidence: 100,
+							BBox:       pt,
+							PixelBBox:  pt.scale(toPixels),
+						})
+					}
+					if len(words) > 0 {
+						lines = append(lines, newLine(words))
+					}
+				}
+			}
+		}
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,160 @@
+package ocr
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+const sampleTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t2550\t3300\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t300\t300\t600\t60\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t300\t300\t300\t60\t96.5\tInvoice\n" +
+	"5\t1\t1\t1\t1\t2\t630\t310\t270\t50\t90.5\tNo.\n" +
+	"5\t1\t1\t1\t2\t1\t300\t400\t150\t60\t40\t  \n" +
+	"5\t1\t1\t1\t2\t2\t480\t400\t150\t60\t50\t42\n"
+
+func TestParseTSV(t *testing.T) {
+	lines, err := parseTSV([]byte(sampleTSV), 300)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %+v", len(lines), lines)
+	}
+
+	first := lines[0]
+	if first.Text != "Invoice No." || len(first.Words) != 2 {
+		t.Fatalf("unexpected first line: %+v", first)
+	}
+	if first.Confidence != 93.5 {
+		t.Errorf("expected mean confidence 93.5, got %v", first.Confidence)
+	}
+	if want := (BBox{X0: 300, Y0: 300, X1: 900, Y1: 360}); first.PixelBBox != want {
+		t.Errorf("unexpected pixel bbox: %+v", first.PixelBBox)
+	}
+	// 300px at 300dpi is one inch, i.e. 72pt
+	if want := (BBox{X0: 72, Y0: 72, X1: 216, Y1: 86.4}); !bboxNear(first.BBox, want) {
+		t.Errorf("unexpected point bbox: %+v", first.BBox)
+	}
+
+	// Empty words are skipped
+	if lines[1].Text != "42" || len(lines[1].Words) != 1 {
+		t.Fatalf("unexpected second line: %+v", lines[1])
+	}
+	if got := linesText(lines); got != "Invoice No.\n42" {
+		t.Errorf("unexpected text: %q", got)
+	}
+}
+
+func TestParseTSV_InvalidNumber(t *testing.T) {
+	data := "5\t1\t1\t1\t1\t1\tleft\t0\t10\t10\t90\tword\n"
+	if _, err := parseTSV([]byte(data), 300); err == nil {
+		t.Fatal("expected error for invalid coordinate")
+	}
+}
+
+const sampleBBoxLayout = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head>
+<title></title>
+<meta name="Producer" content="test"/>
+</head>
+<body>
+<doc>
+  <page width="612.000000" height="792.000000">
+    <flow>
+      <block xMin="72.0" yMin="72.0" xMax="216.0" yMax="96.0">
+        <line xMin="72.0" yMin="72.0" xMax="216.0" yMax="96.0">
+          <word xMin="72.000000" yMin="72.000000" xMax="144.000000" yMax="96.000000">Total</word>
+          <word xMin="150.000000" yMin="72.000000" xMax="216.000000" yMax="96.000000">&amp;Tax</word>
+        </line>
+      </block>
+    </flow>
+  </page>
+</doc>
+</body>
+</html>`
+
+func TestParseBBoxLayout(t *testing.T) {
+	lines, err := parseBBoxLayout([]byte(sampleBBoxLayout), 144)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(lines) != 1 || lines[0].Text != "Total &Tax" {
+		t.Fatalf("unexpected lines: %+v", lines)
+	}
+	word := lines[0].Words[0]
+	if word.Confidence != 100 {
+		t.Errorf("expected text layer confidence 100, got %v", word.Confidence)
+	}
+	if want := (BBox{X0: 72, Y0: 72, X1: 144, Y1: 96}); word.BBox != want {
+		t.Errorf("unexpected point bbox: %+v", word.BBox)
+	}
+	if want := (BBox{X0: 144, Y0: 144, X1: 288, Y1: 192}); word.PixelBBox != want {
+		t.Errorf("unexpected pixel bbox: %+v", word.PixelBBox)
+	}
+}
+
+type stubLayoutRecognizer struct {
+	calls int
+}
+
+func (s *stubLayoutRecognizer) RecognizeLayout(ctx context.Context, pagePath string, opts Options) (string, []Line, error) {
+	s.calls++
+	line := newLine([]Word{{Text: "boxed", Confidence: 80}})
+	return line.Text, []Line{line}, nil
+}
+
+func TestExtractText_LayoutMode(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 2)
+
+	recognizer := &stubRecognizer{}
+	layout := &stubLayoutRecognizer{}
+	p := &Processor{Extractor: &stubExtractor{}, Recognizer: recognizer, LayoutRecognizer: layout}
+
+	pages, err := p.ExtractText(context.Background(), pdfPath, Options{Layout: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if layout.calls != 2 || len(recognizer.calls) != 0 {
+		t.Fatalf("expected layout recognizer for every page, got layout=%d plain=%d", layout.calls, len(recognizer.calls))
+	}
+	for _, page := range pages {
+		if page.Content != "boxed" || len(page.Lines) != 1 || page.Lines[0].Words[0].Confidence != 80 {
+			t.Errorf("unexpected page: %+v", page)
+		}
+	}
+
+	// Without the option the plain recognizer is used and no lines are returned
+	pages, err = p.ExtractText(context.Background(), pdfPath, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recognizer.calls) != 2 || pages[0].Lines != nil {
+		t.Fatalf("expected plain recognition without layout, got %+v", pages)
+	}
+}
+
+func TestProcessor_LayoutRecognizerDefault(t *testing.T) {
+	if _, ok := NewProcessor().layoutRecognizer().(*Tesseract); !ok {
+		t.Error("expected tesseract layout recognizer by default")
+	}
+
+	tess := &Tesseract{Binary: "custom"}
+	p := &Processor{Recognizer: tess}
+	if p.layoutRecognizer() != tess {
+		t.Error("expected layout-capable recognizer to be reused")
+	}
+}
+
+func bboxNear(a, b BBox) bool {
+	const eps = 1e-9
+	return math.Abs(a.X0-b.X0) < eps && math.Abs(a.Y0-b.Y0) < eps &&
+		math.Abs(a.X1-b.X1) < eps && math.Abs(a.Y1-b.Y1) < eps
+}
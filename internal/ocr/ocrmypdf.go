@@ -0,0 +1,87 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"app/internal/metrics"
+)
+
+// OCRmyPDF recognizes pages by shelling out to the ocrmypdf CLI.
+type OCRmyPDF struct {
+	Binary  string
+	Timeout time.Duration
+}
+
+// Recognize runs OCRmyPDF on a single page PDF and returns the sidecar text.
+func (o *OCRmyPDF) Recognize(ctx context.Context, pagePath string, opts Options) (string, error) {
+	outputPDF, err := os.CreateTemp(TempDir(), "ocr-output-*.pdf")
+	if err != nil {
+		return "", fmt.Errorf("create temp output: %w", err)
+	}
+	defer os.Remove(outputPDF.Name())
+	outputPDF.Close()
+
+	return o.RecognizePDF(ctx, pagePath, outputPDF.Name(), opts)
+}
+
+// RecognizePDF runs OCRmyPDF on a single page PDF, keeps the OCRed page at outputPath and returns the sidecar text.
+func (o *OCRmyPDF) RecognizePDF(ctx context.Context, pagePath, outputPath string, opts Options) (string, error) {
+	binary := o.Binary
+	if binary == "" {
+		binary = "ocrmypdf"
+	}
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+
+	// Create temp files
+	sidecarFile, err := os.CreateTemp(TempDir(), "ocr-sidecar-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("create sidecar: %w", err)
+	}
+	defer os.Remove(sidecarFile.Name())
+	defer sidecarFile.Close()
+
+	// Build OCRmyPDF arguments. Pages are already processed in parallel, so each run
+	// uses one worker instead of one per CPU.
+	args := []string{
+		"--sidecar", sidecarFile.Name(),
+		"--quiet",
+		"--jobs", "1",
+		"--rotate-pages-threshold", "0.0",
+		"--force-ocr",
+	}
+	if opts.Language != "" {
+		args = append(args, "--language", opts.Language)
+	}
+	args = append(args, pagePath, outputPath)
+
+	// Execute OCRmyPDF
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, binary, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		err = engineError(ctx, cmdCtx, "ocrmypdf", err, stderr.String())
+		metrics.OCRmyPDFFailures.Inc(failureReason(ctx, err))
+		return "", err
+	}
+
+	// Read sidecar output
+	data, err := os.ReadFile(sidecarFile.Name())
+	if err != nil {
+		return "", fmt.Errorf("read sidecar: %w", err)
+	}
+
+	return strings.TrimSpace(normalizeNewlines(string(data))), nil
+}
@@ -0,0 +1,124 @@
+package ocr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrPageOutOfRange is returned when a page selection names pages the document does not have.
+var ErrPageOutOfRange = errors.New("page out of range")
+
+// lastPage stands for the final page of the document in a pageRange.
+const lastPage = -1
+
+// pageRange is an inclusive range of 1-based page numbers.
+type pageRange struct {
+	from, to int
+}
+
+// PageSet is a parsed page selection such as "1-3,7,last".
+// The zero value selects every page.
+type PageSet struct {
+	ranges []pageRange
+}
+
+// ParsePageSet parses a comma separated list of page numbers and ranges.
+// Either end of a range may be "last". An empty spec selects every page.
+func ParsePageSet(spec string) (PageSet, error) {
+	var set PageSet
+	if strings.TrimSpace(spec) == "" {
+		return set, nil
+	}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		from, to, isRange := strings.Cut(item, "-")
+
+		start, err := parsePageNumber(from)
+		if err != nil {
+			return PageSet{}, fmt.Errorf("invalid page selection %q: %w", item, err)
+		}
+		end := start
+		if isRange {
+			if end, err = parsePageNumber(to); err != nil {
+				return PageSet{}, fmt.Errorf("invalid page selection %q: %w", item, err)
+			}
+		}
+		if start == lastPage && end != lastPage || end != lastPage && start > end {
+			return PageSet{}, fmt.Errorf("invalid page selection %q: range is reversed", item)
+		}
+		set.ranges = append(set.ranges, pageRange{from: start, to: end})
+	}
+	return set, nil
+}
+
+func parsePageNumber(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "last") {
+		return lastPage, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not a page number", s)
+	}
+	return n, nil
+}
+
+// All reports whether the set selects every page.
+func (s PageSet) All() bool {
+	return len(s.ranges) == 0
+}
+
+// Pages returns the selected page numbers of a document with pageCount pages, in ascending order.
+func (s PageSet) Pages(pageCount int) ([]int, error) {
+	selected := make(map[int]bool)
+	for _, r := range s.ranges {
+		from, to := r.from, r.to
+		if from == lastPage {
+			from = pageCount
+		}
+		if to == lastPage {
+			to = pageCount
+		}
+		if last := max(from, to); last > pageCount {
+			return nil, fmt.Errorf("%w: page %d of %d", ErrPageOutOfRange, last, pageCount)
+		}
+		for page := from; page <= to; page++ {
+			selected[page] = true
+		}
+	}
+	if s.All() {
+		for page := 1; page <= pageCount; page++ {
+			selected[page] = true
+		}
+	}
+
+	pages := make([]int, 0, len(selected))
+	for page := range selected {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+	return pages, nil
+}
+
+// String returns the selection in canonical form, or "" when every page is selected.
+func (s PageSet) String() string {
+	items := make([]string, len(s.ranges))
+	for i, r := range s.ranges {
+		items[i] = formatPageNumber(r.from)
+		if r.to != r.from {
+			items[i] += "-" + formatPageNumber(r.to)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+func formatPageNumber(n int) string {
+	if n == lastPage {
+		return "last"
+	}
+	return strconv.Itoa(n)
+}
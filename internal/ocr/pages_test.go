@@ -0,0 +1,111 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePageSet(t *testing.T) {
+	tests := []struct {
+		spec      string
+		pageCount int
+		expected  []int
+		canonical string
+	}{
+		{"", 3, []int{1, 2, 3}, ""},
+		{"2", 3, []int{2}, "2"},
+		{"1-3,7,last", 10, []int{1, 2, 3, 7, 10}, "1-3,7,last"},
+		{" 4 - last , 1 ", 5, []int{1, 4, 5}, "4-last,1"},
+		{"LAST", 4, []int{4}, "last"},
+		{"2-3,3,1-2", 4, []int{1, 2, 3}, "2-3,3,1-2"},
+		{"last-last", 2, []int{2}, "last"},
+	}
+
+	for _, tt := range tests {
+		set, err := ParsePageSet(tt.spec)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.spec, err)
+		}
+		pages, err := set.Pages(tt.pageCount)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.spec, err)
+		}
+		if !reflect.DeepEqual(pages, tt.expected) {
+			t.Errorf("%q: got pages %v, want %v", tt.spec, pages, tt.expected)
+		}
+		if got := set.String(); got != tt.canonical {
+			t.Errorf("%q: got canonical form %q, want %q", tt.spec, got, tt.canonical)
+		}
+	}
+}
+
+func TestParsePageSet_Invalid(t *testing.T) {
+	for _, spec := range []string{"0", "-1", "a", "1-", "3-1", "last-2", "1,,2", "1-2-3", "1.5"} {
+		if _, err := ParsePageSet(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestPageSet_OutOfRange(t *testing.T) {
+	for _, spec := range []string{"4", "1-5", "4-last"} {
+		set, err := ParsePageSet(spec)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", spec, err)
+		}
+		if _, err := set.Pages(3); !errors.Is(err, ErrPageOutOfRange) {
+			t.Errorf("%q: expected ErrPageOutOfRange, got %v", spec, err)
+		}
+	}
+}
+
+func TestExtractText_SelectedPages(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 5)
+
+	rec := &stubRecognizer{}
+	p := &Processor{Extractor: &stubExtractor{}, Recognizer: rec}
+
+	var totals []int
+	opts := Options{ForceOCR: true, Pages: "2,4-last", OnProgress: func(p Progress) { totals = append(totals, p.Total) }}
+	pages, err := p.ExtractText(context.Background(), pdfPath, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []int
+	for _, page := range pages {
+		got = append(got, page.Page)
+	}
+	if !reflect.DeepEqual(got, []int{2, 4, 5}) {
+		t.Fatalf("expected original page numbers 2, 4 and 5, got %v", got)
+	}
+
+	// Only the selected pages are split and recognized
+	var recognized []string
+	for _, call := range rec.calls {
+		recognized = append(recognized, strings.TrimSuffix(filepath.Base(call), ".pdf"))
+	}
+	if len(recognized) != 3 || !strings.Contains(strings.Join(recognized, " "), "page_0004") {
+		t.Errorf("unexpected recognizer calls: %v", recognized)
+	}
+	for _, call := range recognized {
+		if call == "page_0001" || call == "page_0003" {
+			t.Errorf("unselected page %s was recognized", call)
+		}
+	}
+	for _, total := range totals {
+		if total != 3 {
+			t.Errorf("expected progress totals of 3 selected pages, got %v", totals)
+			break
+		}
+	}
+
+	if _, err := p.ExtractText(context.Background(), pdfPath, Options{Pages: "6"}); !errors.Is(err, ErrPageOutOfRange) {
+		t.Errorf("expected ErrPageOutOfRange, got %v", err)
+	}
+}
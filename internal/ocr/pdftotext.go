@@ -0,0 +1,63 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+)
+
+// Pdftotext extracts existing text layers using pdftotext (poppler-utils).
+type Pdftotext struct {
+	Binary string
+	DPI    int // Resolution pixel coordinates refer to in layout output (default: 300)
+}
+
+// ExtractPageText extracts text content from a PDF page.
+// A missing or failing pdftotext yields an empty string so the page falls back to OCR.
+func (e *Pdftotext) ExtractPageText(ctx context.Context, pagePath string) (string, error) {
+	// Use pdftotext from poppler-utils for reliable text extraction
+	out, ok := e.run(ctx, "-layout", pagePath, "-")
+	if !ok {
+		// pdftotext not available or failed, return empty string
+		return "", nil
+	}
+
+	return normalizeNewlines(out), nil
+}
+
+// ExtractPageLayout extracts text content with word boxes from a PDF page using pdftotext -bbox-layout.
+// A missing or failing pdftotext yields no text so the page falls back to OCR.
+func (e *Pdftotext) ExtractPageLayout(ctx context.Context, pagePath string) (string, []Line, error) {
+	out, ok := e.run(ctx, "-bbox-layout", pagePath, "-")
+	if !ok {
+		return "", nil, nil
+	}
+
+	dpi := e.DPI
+	if dpi <= 0 {
+		dpi = defaultDPI
+	}
+	lines, err := parseBBoxLayout([]byte(out), dpi)
+	if err != nil {
+		return "", nil, err
+	}
+	return linesText(lines), lines, nil
+}
+
+// run executes pdftotext and reports whether it succeeded.
+func (e *Pdftotext) run(ctx context.Context, args ...string) (string, bool) {
+	binary := e.Binary
+	if binary == "" {
+		binary = "pdftotext"
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", false
+	}
+	return stdout.String(), true
+}
@@ -1,8 +1,8 @@
 package ocr
 
 import (
+	"app/internal/metrics"
 	"app/pkg"
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -10,8 +10,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
@@ -22,10 +25,42 @@ const (
 	DefaultTextThreshold = 50
 )
 
+// Page sources reported in PageContent.Source.
+const (
+	SourceTextLayer = "text_layer"
+	SourceOCR       = "ocr"
+)
+
+// Watermark removal outcomes reported in PageContent.Watermark.
+const (
+	WatermarkSkipped = "skipped" // Removal was not attempted
+	WatermarkRemoved = "removed"
+	WatermarkNone    = "none" // The page had no watermark
+	WatermarkFailed  = "failed"
+)
+
+// LLM correction outcomes reported in PageContent.Correction.
+const (
+	CorrectionApplied = "corrected"
+	CorrectionSkipped = "skipped" // The page came from a text layer or was recognized confidently
+	CorrectionFailed  = "failed"  // The model failed or its answer was rejected; Content is the raw text
+)
+
 // PageContent represents OCR text for a single page.
 type PageContent struct {
 	Page    int    `json:"page"`
 	Content string `json:"content"`
+	Lines   []Line `json:"lines,omitempty"` // Populated when Options.Layout is set
+
+	Source        string `json:"source,omitempty"`          // SourceTextLayer or SourceOCR
+	Chars         int    `json:"chars"`                     // Characters in Content
+	OCRDurationMS int64  `json:"ocr_duration_ms,omitempty"` // Time spent recognizing the page
+	Watermark     string `json:"watermark,omitempty"`       // Watermark removal outcome
+	Language      string `json:"language,omitempty"`        // Languages the page was OCRed with
+	Script        string `json:"script,omitempty"`          // Script detected for Options.Language "auto"
+
+	Correction string `json:"correction,omitempty"`  // LLM correction outcome when Options.Correct is set
+	RawContent string `json:"raw_content,omitempty"` // Recognized text before LLM correction; Lines keep the raw words
 }
 
 // Options controls the OCR command invocation.
@@ -33,20 +68,71 @@ type Options struct {
 	Language        string
 	TextThreshold   int  // Minimum characters to consider page has text (default: 50)
 	ForceOCR        bool // Force OCR even if text exists
-	RemoveWatermark bool // Remove watermark before processing (default: true)
+	RemoveWatermark bool // Remove watermarks before processing; the only switch for removal
+	Concurrency     int  // Overrides Processor.Concurrency when > 0
+	Layout          bool // Include lines and words with bounding boxes and confidence
+	Correct         bool // Correct recognized text with an LLM; applied by the service after ExtractText
+
+	Pages         string            // Page selection such as "1-3,7,last" (default: every page)
+	Normalization pkg.Normalization // How page text is cleaned up (default: collapsed)
+
+	// Password opens encrypted PDFs. It is never serialized.
+	Password string `json:"-"`
+
+	// SearchablePDF, when set, is the path ExtractText writes the document to with an
+	// OCR text layer on every recognized page. Pages that already had text are kept as they were.
+	// Word boxes are not collected for recognized pages in this mode.
+	SearchablePDF string `json:"-"`
+
+	// PostProcess, when set, runs on each finished page in its own worker, in parallel with
+	// other pages, before the page is reported and returned. It should be safe for concurrent use.
+	PostProcess func(ctx context.Context, page PageContent) PageContent `json:"-"`
+
+	// OnProgress is called once after the PDF is split and again as each page finishes.
+	// Calls are serialized but pages may finish out of order.
+	OnProgress func(Progress) `json:"-"`
+}
+
+// Progress reports how far ExtractText has come.
+type Progress struct {
+	Done  int          // Pages finished so far
+	Total int          // Pages selected for processing
+	Page  *PageContent // The page that just finished; nil for the initial event
 }
 
-// Processor wraps OCRmyPDF CLI invocation.
+// Processor splits PDFs into pages and runs them through an OCR engine.
 type Processor struct {
-	Binary  string
-	Timeout time.Duration
+	Binary        string
+	Timeout       time.Duration
+	Concurrency   int // Maximum pages of a document processed in parallel (default: 1)
+	PageLimit     int // Maximum pages processed at once across all documents (default: no limit)
+	MaxPages      int // Maximum pages processed per document (default: no limit)
+	TextThreshold int // Used when Options.TextThreshold is not set (default: 150)
+
+	Extractor  TextExtractor // Reads existing text layers (default: pdftotext)
+	Recognizer Recognizer    // OCRs pages without text (default: OCRmyPDF using Binary and Timeout)
+
+	// LayoutRecognizer OCRs pages when Options.Layout is set
+	// (default: Recognizer if it supports layouts, otherwise tesseract TSV output)
+	LayoutRecognizer LayoutRecognizer
+
+	// ScriptDetector picks page languages for Options.Language "auto" (default: tesseract OSD)
+	// from AutoLanguages (default: DefaultAutoLanguages)
+	ScriptDetector ScriptDetector
+	AutoLanguages  []string
+
+	pageSlotsOnce sync.Once
+	pageSlots     chan struct{} // Shared by every ExtractText call when PageLimit is set
 }
 
-// NewProcessor returns a Processor with sane defaults.
+// NewProcessor returns a Processor with sane defaults: documents use every CPU, but
+// concurrent documents share them, so the host runs at most one page per CPU.
 func NewProcessor() *Processor {
 	return &Processor{
-		Binary:  "ocrmypdf",
-		Timeout: 2 * time.Minute,
+		Binary:      "ocrmypdf",
+		Timeout:     2 * time.Minute,
+		Concurrency: runtime.NumCPU(),
+		PageLimit:   runtime.NumCPU(),
 	}
 }
 
@@ -57,89 +143,346 @@ func (p *Processor) ExtractText(ctx context.Context, pdfPath string, opts Option
 	}
 
 	// Set defaults
+	if opts.TextThreshold <= 0 {
+		opts.TextThreshold = p.TextThreshold
+	}
 	if opts.TextThreshold <= 0 {
 		opts.TextThreshold = 150
 	}
 
-	// Split PDF into individual pages
-	pageFiles, tempDir, err := p.splitPDFPages(pdfPath)
+	selection, err := ParsePageSet(opts.Pages)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the selected pages into individual files
+	pageNums, pageFiles, tempDir, err := p.splitPDFPages(pdfPath, selection, opts.Password)
 	if err != nil {
 		return nil, fmt.Errorf("split pdf: %w", err)
 	}
 	defer os.RemoveAll(tempDir)
 
-	var results []PageContent
+	pages := make([]PageContent, len(pageFiles))
+	pagePDFs := make([]string, len(pageFiles))
 
-	for i, pageFile := range pageFiles {
-		pageNum := i + 1
-		fmt.Println("pageFile", pageFile)
-
-		// Remove watermark if enabled (default: true when not explicitly set)
-		if opts.RemoveWatermark || (!opts.ForceOCR && opts.TextThreshold > 0) {
-			if err := p.removeWatermark(pageFile); err != nil {
-				// Log but continue - watermark removal is best effort
-				_ = err
-			}
+	var (
+		progressMu sync.Mutex
+		done       int
+	)
+	report := func(page *PageContent) {
+		if opts.OnProgress == nil {
+			return
+		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		if page != nil {
+			done++
 		}
+		opts.OnProgress(Progress{Done: done, Total: len(pageFiles), Page: page})
+	}
+	report(nil)
 
-		var text string
+	// Cancel outstanding pages as soon as one of them fails
+	workCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		// Try to extract existing text first (unless ForceOCR is set)
-		if !opts.ForceOCR {
-			extractedText, err := p.extractTextFromPage(pageFile)
-			if err == nil && p.hasSignificantText(extractedText, opts.TextThreshold) {
-				text = strings.TrimSpace(extractedText)
-			}
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, p.concurrency(opts))
+
+dispatch:
+	for i, pageFile := range pageFiles {
+		select {
+		case sem <- struct{}{}:
+		case <-workCtx.Done():
+			break dispatch
 		}
 
-		// If no significant text found, run OCR on this page
-		if text == "" {
-			ocrText, err := p.ocrSinglePage(ctx, pageFile, opts)
+		wg.Add(1)
+		go func(i int, pageFile string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			pageNum := pageNums[i]
+			release, err := p.acquirePage(workCtx)
+			if err != nil {
+				return
+			}
+			page, pagePDF, err := p.processPage(workCtx, pageNum, pageFile, opts)
+			release()
 			if err != nil {
-				return nil, fmt.Errorf("ocr page %d: %w", pageNum, err)
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("ocr page %d: %w", pageNum, err)
+					cancel()
+				})
+				return
 			}
-			text = ocrText
+			page.Content = pkg.Normalize(page.Content, opts.Normalization)
+			page.Chars = utf8.RuneCountInString(page.Content)
+			if opts.PostProcess != nil {
+				// Post-processed text is normalized again so it keeps the requested form
+				page = opts.PostProcess(workCtx, page)
+				page.Content = pkg.Normalize(page.Content, opts.Normalization)
+				page.Chars = utf8.RuneCountInString(page.Content)
+			}
+			pages[i] = page
+			pagePDFs[i] = pagePDF
+			report(&page)
+		}(i, pageFile)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if opts.SearchablePDF != "" {
+		if err := mergePages(pagePDFs, opts.SearchablePDF); err != nil {
+			return nil, fmt.Errorf("assemble searchable pdf: %w", err)
 		}
+	}
 
-		// Only add pages with content
-		if text != "" {
-			results = append(results, PageContent{
-				Page:    pageNum,
-				Content: pkg.RemoveExtraSpaces(text),
-			})
+	// Only return pages with content, in page order
+	var results []PageContent
+	for _, page := range pages {
+		if page.Content != "" {
+			results = append(results, page)
 		}
 	}
 
 	return results, nil
 }
 
-// splitPDFPages splits a PDF into individual page files.
-func (p *Processor) splitPDFPages(pdfPath string) ([]string, string, error) {
+// concurrency returns the number of pages that may be processed in parallel.
+func (p *Processor) concurrency(opts Options) int {
+	if opts.Concurrency > 0 {
+		return opts.Concurrency
+	}
+	if p.Concurrency > 0 {
+		return p.Concurrency
+	}
+	return 1
+}
+
+// acquirePage waits for one of the PageLimit slots shared by all documents and returns
+// the function releasing it. It fails only when ctx is done.
+func (p *Processor) acquirePage(ctx context.Context) (func(), error) {
+	if p.PageLimit <= 0 {
+		return func() {}, nil
+	}
+	p.pageSlotsOnce.Do(func() {
+		p.pageSlots = make(chan struct{}, p.PageLimit)
+	})
+	select {
+	case p.pageSlots <- struct{}{}:
+		return func() { <-p.pageSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// extractor returns the configured text-layer extractor or the pdftotext default.
+func (p *Processor) extractor() TextExtractor {
+	if p.Extractor != nil {
+		return p.Extractor
+	}
+	return &Pdftotext{}
+}
+
+// recognizer returns the configured OCR recognizer or the OCRmyPDF default.
+func (p *Processor) recognizer() Recognizer {
+	if p.Recognizer != nil {
+		return p.Recognizer
+	}
+	return &OCRmyPDF{Binary: p.Binary, Timeout: p.Timeout}
+}
+
+// layoutRecognizer returns the recognizer used when word boxes are requested.
+func (p *Processor) layoutRecognizer() LayoutRecognizer {
+	if p.LayoutRecognizer != nil {
+		return p.LayoutRecognizer
+	}
+	if rec, ok := p.recognizer().(LayoutRecognizer); ok {
+		return rec
+	}
+	return &Tesseract{Timeout: p.Timeout}
+}
+
+// processPage removes watermarks, extracts the existing text layer and falls back to OCR for a single page.
+// It also returns the file that represents the page in a searchable PDF.
+func (p *Processor) processPage(ctx context.Context, pageNum int, pageFile string, opts Options) (PageContent, string, error) {
+	page := PageContent{Page: pageNum, Watermark: WatermarkSkipped}
+
+	// Remove watermarks if enabled
+	workFile := pageFile
+	if opts.RemoveWatermark {
+		// Best effort - fall back to the original page when removal fails
+		cleaned, err := p.removeWatermark(pageFile)
+		switch {
+		case err == nil:
+			workFile = cleaned
+			page.Watermark = WatermarkRemoved
+		case isNoWatermark(err):
+			page.Watermark = WatermarkNone
+		default:
+			page.Watermark = WatermarkFailed
+		}
+	}
+
+	// Try to extract existing text first (unless ForceOCR is set)
+	if !opts.ForceOCR {
+		text, lines, err := p.extractPage(ctx, workFile, opts)
+		if err == nil && p.hasSignificantText(text, opts.TextThreshold) {
+			page.Source = SourceTextLayer
+			page.Content = strings.TrimSpace(text)
+			page.Lines = lines
+			metrics.PagesProcessed.Inc(SourceTextLayer)
+			return page, pageFile, nil
+		}
+	}
+
+	// If no significant text found, run OCR on this page
+	page.Source = SourceOCR
+	start := time.Now()
+	if opts.Language == LanguageAuto {
+		opts.Language, page.Script = p.detectLanguage(ctx, workFile)
+	}
+	page.Language = opts.Language
+
+	if opts.SearchablePDF != "" {
+		outputFile := strings.TrimSuffix(pageFile, ".pdf") + "_ocr.pdf"
+		text, err := p.recognizePDF(ctx, workFile, outputFile, opts)
+		if err != nil {
+			return PageContent{}, "", err
+		}
+		page.Content = text
+		recordOCRPage(&page, start)
+		return page, outputFile, nil
+	}
+
+	text, lines, err := p.recognizePage(ctx, workFile, opts)
+	if err != nil {
+		return PageContent{}, "", err
+	}
+	page.Content = text
+	page.Lines = lines
+	recordOCRPage(&page, start)
+	return page, pageFile, nil
+}
+
+// recordOCRPage sets the OCR duration of page and records it in the metrics.
+func recordOCRPage(page *PageContent, start time.Time) {
+	elapsed := time.Since(start)
+	page.OCRDurationMS = elapsed.Milliseconds()
+	metrics.PagesProcessed.Inc(SourceOCR)
+	metrics.PageOCRDuration.Observe(elapsed.Seconds())
+}
+
+// detectLanguage picks the candidate languages written in the page's script. When detection
+// fails, for example on pages with too little text, every candidate is used.
+func (p *Processor) detectLanguage(ctx context.Context, pageFile string) (string, string) {
+	candidates := p.AutoLanguages
+	if len(candidates) == 0 {
+		candidates = DefaultAutoLanguages
+	}
+
+	detector := p.ScriptDetector
+	if detector == nil {
+		detector = &Tesseract{Timeout: p.Timeout}
+	}
+	script, err := detector.DetectScript(ctx, pageFile)
+	if err != nil {
+		return strings.Join(candidates, "+"), ""
+	}
+	return strings.Join(languagesForScript(script, candidates), "+"), script
+}
+
+// extractPage reads the existing text layer, with word boxes when requested and supported.
+func (p *Processor) extractPage(ctx context.Context, pageFile string, opts Options) (string, []Line, error) {
+	extractor := p.extractor()
+	if layout, ok := extractor.(LayoutExtractor); ok && opts.Layout {
+		return layout.ExtractPageLayout(ctx, pageFile)
+	}
+	text, err := extractor.ExtractPageText(ctx, pageFile)
+	return text, nil, err
+}
+
+// recognizePage runs OCR on the page, with word boxes when requested.
+func (p *Processor) recognizePage(ctx context.Context, pageFile string, opts Options) (string, []Line, error) {
+	if opts.Layout {
+		return p.layoutRecognizer().RecognizeLayout(ctx, pageFile, opts)
+	}
+	text, err := p.recognizer().Recognize(ctx, pageFile, opts)
+	return text, nil, err
+}
+
+// recognizePDF runs OCR on the page and writes it with a text layer to outputFile.
+func (p *Processor) recognizePDF(ctx context.Context, pageFile, outputFile string, opts Options) (string, error) {
+	rec, ok := p.recognizer().(PDFRecognizer)
+	if !ok {
+		return "", fmt.Errorf("recognizer %T cannot produce searchable pdfs", p.recognizer())
+	}
+	return rec.RecognizePDF(ctx, pageFile, outputFile, opts)
+}
+
+// mergePages joins single page PDFs, in order, into one document at outputPath.
+func mergePages(pageFiles []string, outputPath string) error {
+	conf := model.NewDefaultConfiguration()
+	return api.MergeCreateFile(pageFiles, outputPath, false, conf)
+}
+
+// splitPDFPages splits the selected pages of a PDF into individual page files,
+// decrypting it with password first when it is encrypted.
+// It returns the original page numbers alongside the files.
+func (p *Processor) splitPDFPages(pdfPath string, selection PageSet, password string) ([]int, []string, string, error) {
 	// Create temp directory for split pages
-	tempDir, err := os.MkdirTemp("", "ocr-pages-*")
+	tempDir, err := os.MkdirTemp(TempDir(), "ocr-pages-*")
 	if err != nil {
-		return nil, "", fmt.Errorf("create temp dir: %w", err)
+		return nil, nil, "", fmt.Errorf("create temp dir: %w", err)
+	}
+
+	decrypted := filepath.Join(tempDir, "decrypted.pdf")
+	if ok, err := DecryptPDF(pdfPath, decrypted, password); err != nil {
+		os.RemoveAll(tempDir)
+		return nil, nil, "", err
+	} else if ok {
+		pdfPath = decrypted
 	}
 
 	// Get page count
 	pageCount, err := api.PageCountFile(pdfPath)
 	if err != nil {
 		os.RemoveAll(tempDir)
-		return nil, "", fmt.Errorf("get page count: %w", err)
+		return nil, nil, "", fmt.Errorf("%w: get page count: %w", ErrInvalidPDF, err)
+	}
+	pageNums, err := selection.Pages(pageCount)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return nil, nil, "", err
+	}
+	if p.MaxPages > 0 && len(pageNums) > p.MaxPages {
+		os.RemoveAll(tempDir)
+		return nil, nil, "", fmt.Errorf("%w: %d pages selected, limit is %d", ErrTooManyPages, len(pageNums), p.MaxPages)
 	}
 
 	conf := model.NewDefaultConfiguration()
 	var pageFiles []string
 
-	// Split each page
-	for i := 1; i <= pageCount; i++ {
+	// Split each selected page
+	for _, i := range pageNums {
 		outputPath := filepath.Join(tempDir, fmt.Sprintf("page_%04d.pdf", i))
 
 		// Extract single page
 		err := api.ExtractPagesFile(pdfPath, tempDir, []string{fmt.Sprintf("%d", i)}, conf)
 		if err != nil {
 			os.RemoveAll(tempDir)
-			return nil, "", fmt.Errorf("extract page %d: %w", i, err)
+			return nil, nil, "", fmt.Errorf("extract page %d: %w", i, err)
 		}
 
 		// pdfcpu creates files with pattern: originalname_page_N.pdf
@@ -150,7 +493,7 @@ func (p *Processor) splitPDFPages(pdfPath string) ([]string, string, error) {
 		if _, err := os.Stat(expectedName); err == nil {
 			if err := os.Rename(expectedName, outputPath); err != nil {
 				os.RemoveAll(tempDir)
-				return nil, "", fmt.Errorf("rename page %d: %w", i, err)
+				return nil, nil, "", fmt.Errorf("rename page %d: %w", i, err)
 			}
 		} else {
 			// Try alternate naming pattern
@@ -158,62 +501,38 @@ func (p *Processor) splitPDFPages(pdfPath string) ([]string, string, error) {
 			if _, err := os.Stat(altName); err == nil {
 				if err := os.Rename(altName, outputPath); err != nil {
 					os.RemoveAll(tempDir)
-					return nil, "", fmt.Errorf("rename page %d: %w", i, err)
+					return nil, nil, "", fmt.Errorf("rename page %d: %w", i, err)
 				}
 			} else {
 				os.RemoveAll(tempDir)
-				return nil, "", fmt.Errorf("find extracted page %d file", i)
+				return nil, nil, "", fmt.Errorf("find extracted page %d file", i)
 			}
 		}
 
 		pageFiles = append(pageFiles, outputPath)
 	}
 
-	return pageFiles, tempDir, nil
+	return pageNums, pageFiles, tempDir, nil
 }
 
-// removeWatermark attempts to remove watermarks from a PDF page.
-func (p *Processor) removeWatermark(pagePath string) error {
+// removeWatermark writes a copy of a PDF page without watermarks next to it and returns its path.
+func (p *Processor) removeWatermark(pagePath string) (string, error) {
 	conf := model.NewDefaultConfiguration()
-
-	// Create temp file for output
-	tempFile, err := os.CreateTemp("", "ocr-nowm-*.pdf")
-	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
-	}
-	tempPath := tempFile.Name()
-	tempFile.Close()
+	outputPath := strings.TrimSuffix(pagePath, ".pdf") + "_nowm.pdf"
 
 	// Try to remove watermarks
-	if err := api.RemoveWatermarksFile(pagePath, tempPath, nil, conf); err != nil {
-		os.Remove(tempPath)
+	if err := api.RemoveWatermarksFile(pagePath, outputPath, nil, conf); err != nil {
+		os.Remove(outputPath)
 		// Watermark removal failed, but this is not critical
-		return err
-	}
-
-	// Replace original with watermark-removed version
-	if err := os.Rename(tempPath, pagePath); err != nil {
-		os.Remove(tempPath)
-		return fmt.Errorf("replace original: %w", err)
+		return "", err
 	}
 
-	return nil
+	return outputPath, nil
 }
 
-// extractTextFromPage extracts text content from a PDF page using pdftotext (poppler-utils).
-func (p *Processor) extractTextFromPage(pagePath string) (string, error) {
-	// Use pdftotext from poppler-utils for reliable text extraction
-	cmd := exec.Command("pdftotext", "-layout", pagePath, "-")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		// pdftotext not available or failed, return empty string
-		return "", nil
-	}
-
-	return normalizeNewlines(stdout.String()), nil
+// isNoWatermark reports whether a watermark removal error only means there was nothing to remove.
+func isNoWatermark(err error) bool {
+	return strings.Contains(err.Error(), "no watermarks found")
 }
 
 // hasSignificantText checks if the text has enough content to be considered valid.
@@ -227,65 +546,6 @@ func (p *Processor) hasSignificantText(text string, threshold int) bool {
 	return len(cleaned) >= threshold
 }
 
-// ocrSinglePage runs OCRmyPDF on a single page PDF.
-func (p *Processor) ocrSinglePage(ctx context.Context, pagePath string, opts Options) (string, error) {
-	binary := p.Binary
-	if binary == "" {
-		binary = "ocrmypdf"
-	}
-	timeout := p.Timeout
-	if timeout <= 0 {
-		timeout = 2 * time.Minute
-	}
-
-	// Create temp files
-	sidecarFile, err := os.CreateTemp("", "ocr-sidecar-*.txt")
-	if err != nil {
-		return "", fmt.Errorf("create sidecar: %w", err)
-	}
-	defer os.Remove(sidecarFile.Name())
-	defer sidecarFile.Close()
-
-	outputPDF, err := os.CreateTemp("", "ocr-output-*.pdf")
-	if err != nil {
-		return "", fmt.Errorf("create temp output: %w", err)
-	}
-	defer os.Remove(outputPDF.Name())
-	defer outputPDF.Close()
-
-	// Build OCRmyPDF arguments
-	args := []string{
-		"--sidecar", sidecarFile.Name(),
-		"--quiet",
-		"--rotate-pages-threshold", "0.0",
-		"--force-ocr",
-	}
-	if opts.Language != "" {
-		args = append(args, "--language", opts.Language)
-	}
-	args = append(args, pagePath, outputPDF.Name())
-
-	// Execute OCRmyPDF
-	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(cmdCtx, binary, args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("ocrmypdf: %w - %s", err, stderr.String())
-	}
-
-	// Read sidecar output
-	data, err := os.ReadFile(sidecarFile.Name())
-	if err != nil {
-		return "", fmt.Errorf("read sidecar: %w", err)
-	}
-
-	return strings.TrimSpace(normalizeNewlines(string(data))), nil
-}
-
 func parseSidecar(path string) ([]PageContent, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -324,7 +584,7 @@ func normalizeNewlines(in string) string {
 
 // SaveUploadedFile copies the provided reader to a temporary PDF file.
 func SaveUploadedFile(r io.Reader) (string, func(), error) {
-	tmpFile, err := os.CreateTemp("", "ocr-input-*.pdf")
+	tmpFile, err := os.CreateTemp(TempDir(), "ocr-input-*.pdf")
 	if err != nil {
 		return "", nil, fmt.Errorf("create temp pdf: %w", err)
 	}
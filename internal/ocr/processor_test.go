@@ -1,9 +1,21 @@
 package ocr
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"app/internal/metrics"
+	"app/pkg"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
 )
 
 func TestParseSidecarBytes(t *testing.T) {
@@ -148,6 +160,335 @@ func TestNewProcessor(t *testing.T) {
 	if p.Timeout != 2*60*1000000000 { // 2 minutes in nanoseconds
 		t.Errorf("expected timeout 2 minutes, got %v", p.Timeout)
 	}
+	if p.Concurrency < 1 || p.PageLimit < 1 {
+		t.Errorf("expected positive concurrency and page limit, got %d and %d", p.Concurrency, p.PageLimit)
+	}
+}
+
+func TestProcessorConcurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor int
+		option    int
+		expected  int
+	}{
+		{name: "zero value is sequential", expected: 1},
+		{name: "processor setting", processor: 4, expected: 4},
+		{name: "option overrides processor", processor: 4, option: 2, expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Processor{Concurrency: tt.processor}
+			if got := p.concurrency(Options{Concurrency: tt.option}); got != tt.expected {
+				t.Errorf("concurrency() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractText_ParallelKeepsPageOrder(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 6)
+	logPath := filepath.Join(dir, "calls.log")
+
+	// Earlier pages finish last so completion order differs from page order
+	binary := writeFakeOCR(t, dir, fmt.Sprintf(`echo "start $name" >> %[1]q
+case "$name" in
+  page_0001) sleep 0.4 ;;
+  page_0002) sleep 0.2 ;;
+  *) sleep 0.1 ;;
+esac
+printf 'text of %%s' "$name" > "$sidecar"
+echo "end $name" >> %[1]q`, logPath))
+
+	p := &Processor{Binary: binary, Timeout: 10 * time.Second, Concurrency: 2}
+	pages, err := p.ExtractText(context.Background(), pdfPath, Options{ForceOCR: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pages) != 6 {
+		t.Fatalf("expected 6 pages, got %d: %+v", len(pages), pages)
+	}
+	for i, page := range pages {
+		want := fmt.Sprintf("text of page_%04d", i+1)
+		if page.Page != i+1 || page.Content != want {
+			t.Errorf("page %d: got %+v, want content %q", i+1, page, want)
+		}
+	}
+
+	if got := maxOverlap(t, logPath); got > 2 {
+		t.Errorf("expected at most 2 concurrent OCR runs, got %d", got)
+	}
+}
+
+func TestExtractText_OptionsConcurrencyOverride(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 4)
+	logPath := filepath.Join(dir, "calls.log")
+
+	binary := writeFakeOCR(t, dir, fmt.Sprintf(`echo "start $name" >> %[1]q
+sleep 0.1
+printf 'text of %%s' "$name" > "$sidecar"
+echo "end $name" >> %[1]q`, logPath))
+
+	p := &Processor{Binary: binary, Timeout: 10 * time.Second, Concurrency: 4}
+	if _, err := p.ExtractText(context.Background(), pdfPath, Options{ForceOCR: true, Concurrency: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := maxOverlap(t, logPath); got != 1 {
+		t.Errorf("expected sequential OCR runs, got %d concurrent", got)
+	}
+}
+
+func TestExtractText_PageLimitSharedAcrossDocuments(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 3)
+	logPath := filepath.Join(dir, "calls.log")
+
+	binary := writeFakeOCR(t, dir, fmt.Sprintf(`echo "start $name" >> %[1]q
+sleep 0.1
+printf 'text of %%s' "$name" > "$sidecar"
+echo "end $name" >> %[1]q`, logPath))
+
+	p := &Processor{Binary: binary, Timeout: 10 * time.Second, Concurrency: 3, PageLimit: 2}
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.ExtractText(context.Background(), pdfPath, Options{ForceOCR: true}); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := maxOverlap(t, logPath); got > 2 {
+		t.Errorf("expected at most 2 concurrent OCR runs across documents, got %d", got)
+	}
+}
+
+func TestExtractText_CancelsRemainingPagesOnError(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 4)
+
+	binary := writeFakeOCR(t, dir, `if [ "$name" = "page_0002" ]; then
+  echo "broken page" >&2
+  exit 1
+fi
+exec sleep 30`)
+
+	p := &Processor{Binary: binary, Timeout: time.Minute, Concurrency: 4}
+
+	start := time.Now()
+	_, err := p.ExtractText(context.Background(), pdfPath, Options{ForceOCR: true})
+	if err == nil {
+		t.Fatal("expected error from failing page")
+	}
+	if !strings.Contains(err.Error(), "ocr page 2") {
+		t.Fatalf("expected page 2 failure, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected remaining pages to be cancelled, took %v", elapsed)
+	}
+}
+
+func TestExtractText_StopsOnContextCancel(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 4)
+
+	binary := writeFakeOCR(t, dir, `exec sleep 30`)
+
+	p := &Processor{Binary: binary, Timeout: time.Minute, Concurrency: 2}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := p.ExtractText(ctx, pdfPath, Options{ForceOCR: true})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected prompt cancellation, took %v", elapsed)
+	}
+}
+
+func TestExtractText_SearchablePDF(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 3)
+	logPath := filepath.Join(dir, "calls.log")
+
+	// Copy the page through so the output is a valid single page PDF
+	binary := writeFakeOCR(t, dir, fmt.Sprintf(`echo "$name" >> %q
+cp "$input" "$output"
+printf 'text of %%s' "$name" > "$sidecar"`, logPath))
+
+	outputPath := filepath.Join(dir, "searchable.pdf")
+	p := &Processor{
+		Binary:      binary,
+		Timeout:     10 * time.Second,
+		Concurrency: 2,
+		Extractor: &stubExtractor{texts: map[string]string{
+			"page_0002.pdf":      strings.Repeat("x", 200),
+			"page_0002_nowm.pdf": strings.Repeat("x", 200),
+		}},
+	}
+	pages, err := p.ExtractText(context.Background(), pdfPath, Options{SearchablePDF: outputPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("expected 3 pages, got %+v", pages)
+	}
+
+	count, err := api.PageCountFile(outputPath)
+	if err != nil {
+		t.Fatalf("read searchable pdf: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 pages in searchable pdf, got %d", count)
+	}
+
+	calls, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read call log: %v", err)
+	}
+	if strings.Contains(string(calls), "page_0002") {
+		t.Fatalf("expected text-layer page to be kept without OCR, got calls:\n%s", calls)
+	}
+}
+
+func TestExtractText_SearchablePDFUnsupportedRecognizer(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 1)
+
+	p := &Processor{Recognizer: &stubRecognizer{}}
+	_, err := p.ExtractText(context.Background(), pdfPath, Options{ForceOCR: true, SearchablePDF: filepath.Join(dir, "out.pdf")})
+	if err == nil || !strings.Contains(err.Error(), "cannot produce searchable pdfs") {
+		t.Fatalf("expected unsupported recognizer error, got %v", err)
+	}
+}
+
+// writeFakeOCR writes an ocrmypdf stand-in script. The body runs with $sidecar,
+// $input, $output and $name (input base name without extension) set.
+func writeFakeOCR(t *testing.T, dir, body string) string {
+	t.Helper()
+	script := `#!/bin/sh
+sidecar=""
+prev=""
+input=""
+output=""
+for arg in "$@"; do
+  if [ "$prev" = "--sidecar" ]; then
+    sidecar="$arg"
+  fi
+  prev="$arg"
+  input="$output"
+  output="$arg"
+done
+name=$(basename "$input" .pdf)
+: > "$output"
+` + body + "\n"
+
+	path := filepath.Join(dir, "fake-ocrmypdf")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ocr: %v", err)
+	}
+	return path
+}
+
+// writeTestPDF writes a minimal PDF with the given number of blank pages.
+func writeTestPDF(t *testing.T, dir string, pageCount int) string {
+	t.Helper()
+
+	var objects []string
+	kids := make([]string, pageCount)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pageCount),
+	)
+	for i := 0; i < pageCount; i++ {
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << >> >>")
+	}
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	path := filepath.Join(dir, "input.pdf")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatalf("write test pdf: %v", err)
+	}
+	return path
+}
+
+// maxOverlap returns the highest number of OCR runs active at once in a fake OCR log.
+func maxOverlap(t *testing.T, logPath string) int {
+	t.Helper()
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatalf("open call log: %v", err)
+	}
+	defer f.Close()
+
+	active, highest := 0, 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		switch {
+		case strings.HasPrefix(scanner.Text(), "start "):
+			active++
+		case strings.HasPrefix(scanner.Text(), "end "):
+			active--
+		}
+		if active > highest {
+			highest = active
+		}
+	}
+	return highest
+}
+
+func TestExtractText_RecordsPageMetrics(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 3)
+
+	textBefore := metrics.PagesProcessed.Value(SourceTextLayer)
+	ocrBefore := metrics.PagesProcessed.Value(SourceOCR)
+	durationsBefore := metrics.PageOCRDuration.Count()
+
+	p := &Processor{
+		Extractor:  &stubExtractor{texts: map[string]string{"page_0001.pdf": strings.Repeat("text ", 50)}},
+		Recognizer: &stubRecognizer{},
+	}
+	if _, err := p.ExtractText(context.Background(), pdfPath, Options{TextThreshold: 10}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := metrics.PagesProcessed.Value(SourceTextLayer) - textBefore; got != 1 {
+		t.Errorf("expected 1 text layer page, got %v", got)
+	}
+	if got := metrics.PagesProcessed.Value(SourceOCR) - ocrBefore; got != 2 {
+		t.Errorf("expected 2 OCR pages, got %v", got)
+	}
+	if got := metrics.PageOCRDuration.Count() - durationsBefore; got != 2 {
+		t.Errorf("expected 2 OCR durations, got %d", got)
+	}
 }
 
 func TestOptionsDefaults(t *testing.T) {
@@ -163,8 +504,77 @@ func TestOptionsDefaults(t *testing.T) {
 		t.Error("expected ForceOCR false by default")
 	}
 
-	// RemoveWatermark should default to false (but treated as true in logic)
+	// RemoveWatermark should default to false; the handler turns it on unless force_ocr is set
 	if opts.RemoveWatermark {
 		t.Error("expected RemoveWatermark false by default")
 	}
 }
+
+func TestExtractText_PostProcessRunsInParallel(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 2)
+
+	// Each page waits for the other, which only finishes when post-processing is not serialized
+	arrived := make(chan struct{}, 2)
+	var reported []int
+	opts := Options{
+		ForceOCR: true,
+		PostProcess: func(ctx context.Context, page PageContent) PageContent {
+			arrived <- struct{}{}
+			deadline := time.After(5 * time.Second)
+			for len(arrived) < 2 {
+				select {
+				case <-deadline:
+					page.Content = "timed out"
+					return page
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+			page.Content = "post " + page.Content
+			return page
+		},
+		OnProgress: func(p Progress) {
+			if p.Page != nil && strings.HasPrefix(p.Page.Content, "post ") {
+				reported = append(reported, p.Page.Page)
+			}
+		},
+	}
+
+	p := &Processor{Recognizer: &stubRecognizer{}, Concurrency: 2}
+	pages, err := p.ExtractText(context.Background(), pdfPath, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, page := range pages {
+		if !strings.HasPrefix(page.Content, "post ") {
+			t.Fatalf("expected post-processed pages, got %+v", pages)
+		}
+	}
+	if len(reported) != 2 {
+		t.Fatalf("expected post-processed pages to be reported, got %v", reported)
+	}
+}
+
+func TestExtractText_PostProcessedTextIsNormalized(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeTestPDF(t, dir, 1)
+
+	// A corrector may answer with line breaks the requested normalization removes
+	opts := Options{
+		ForceOCR:      true,
+		Normalization: pkg.NormalizationCollapsed,
+		PostProcess: func(ctx context.Context, page PageContent) PageContent {
+			page.Content = "Corrected  text\non two\n\nlines "
+			return page
+		},
+	}
+
+	p := &Processor{Recognizer: &stubRecognizer{}}
+	pages, err := p.ExtractText(context.Background(), pdfPath, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Corrected text on two lines"; pages[0].Content != want || pages[0].Chars != len(want) {
+		t.Fatalf("expected collapsed %q with %d chars, got %q with %d", want, len(want), pages[0].Content, pages[0].Chars)
+	}
+}
@@ -0,0 +1,44 @@
+package ocr
+
+// Summary aggregates the per-page metadata of an ExtractText result.
+type Summary struct {
+	Pages             int      `json:"pages"`
+	TextLayerPages    int      `json:"text_layer_pages"`
+	OCRPages          int      `json:"ocr_pages"`
+	Chars             int      `json:"chars"`
+	OCRDurationMS     int64    `json:"ocr_duration_ms"`
+	WatermarksRemoved int      `json:"watermarks_removed"`
+	WatermarkFailures int      `json:"watermark_failures"`
+	Languages         []string `json:"languages,omitempty"` // Distinct OCR languages, in first-seen order
+}
+
+// Summarize builds the document-level summary of the given pages.
+func Summarize(pages []PageContent) Summary {
+	var s Summary
+	seen := make(map[string]bool)
+	for _, page := range pages {
+		s.Pages++
+		s.Chars += page.Chars
+		s.OCRDurationMS += page.OCRDurationMS
+
+		switch page.Source {
+		case SourceTextLayer:
+			s.TextLayerPages++
+		case SourceOCR:
+			s.OCRPages++
+		}
+
+		switch page.Watermark {
+		case WatermarkRemoved:
+			s.WatermarksRemoved++
+		case WatermarkFailed:
+			s.WatermarkFailures++
+		}
+
+		if page.Language != "" && !seen[page.Language] {
+			seen[page.Language] = true
+			s.Languages = append(s.Languages, page.Language)
+		}
+	}
+	return s
+}
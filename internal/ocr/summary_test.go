@@ -0,0 +1,36 @@
+package ocr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	pages := []PageContent{
+		{Page: 1, Source: SourceTextLayer, Chars: 120, Watermark: WatermarkRemoved},
+		{Page: 2, Source: SourceOCR, Chars: 80, OCRDurationMS: 1500, Watermark: WatermarkFailed, Language: "eng+ind"},
+		{Page: 4, Source: SourceOCR, Chars: 40, OCRDurationMS: 500, Watermark: WatermarkNone, Language: "eng+ind"},
+		{Page: 5, Source: SourceOCR, Chars: 10, OCRDurationMS: 250, Watermark: WatermarkSkipped, Language: "chi_sim"},
+	}
+
+	got := Summarize(pages)
+	want := Summary{
+		Pages:             4,
+		TextLayerPages:    1,
+		OCRPages:          3,
+		Chars:             250,
+		OCRDurationMS:     2250,
+		WatermarksRemoved: 1,
+		WatermarkFailures: 1,
+		Languages:         []string{"eng+ind", "chi_sim"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Summarize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	if got := Summarize(nil); !reflect.DeepEqual(got, Summary{}) {
+		t.Fatalf("expected zero summary, got %+v", got)
+	}
+}
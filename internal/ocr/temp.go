@@ -0,0 +1,52 @@
+package ocr
+
+import (
+	"os"
+	"sync"
+)
+
+// TempPrefix starts the name of every temp file and directory the service creates.
+const TempPrefix = "ocr-"
+
+var tempRoot struct {
+	sync.RWMutex
+	dir string
+}
+
+// CreateTempRoot creates a private directory under os.TempDir for every temp file and
+// directory of this process, so RemoveTempRoot can clean up work interrupted at shutdown
+// without touching files of other processes. Until it is called, TempDir is os.TempDir.
+func CreateTempRoot() (string, error) {
+	dir, err := os.MkdirTemp("", TempPrefix+"root-*")
+	if err != nil {
+		return "", err
+	}
+	tempRoot.Lock()
+	tempRoot.dir = dir
+	tempRoot.Unlock()
+	return dir, nil
+}
+
+// TempDir returns the directory temp files are created in: the root made by
+// CreateTempRoot, or os.TempDir when there is none.
+func TempDir() string {
+	tempRoot.RLock()
+	defer tempRoot.RUnlock()
+	if tempRoot.dir == "" {
+		return os.TempDir()
+	}
+	return tempRoot.dir
+}
+
+// RemoveTempRoot removes the root made by CreateTempRoot with everything in it, and
+// reverts TempDir to os.TempDir.
+func RemoveTempRoot() error {
+	tempRoot.Lock()
+	dir := tempRoot.dir
+	tempRoot.dir = ""
+	tempRoot.Unlock()
+	if dir == "" {
+		return nil
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,49 @@
+package ocr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTempRoot(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	if TempDir() != os.TempDir() {
+		t.Fatalf("expected os.TempDir without a root, got %s", TempDir())
+	}
+
+	// Files of other processes sharing the temp dir, such as a persistent cache
+	other := filepath.Join(os.TempDir(), TempPrefix+"cache")
+	if err := os.Mkdir(other, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := CreateTempRoot()
+	if err != nil {
+		t.Fatalf("create temp root: %v", err)
+	}
+	t.Cleanup(func() { RemoveTempRoot() })
+	if TempDir() != root || filepath.Dir(root) != os.TempDir() {
+		t.Fatalf("expected temp files under %s, got %s", root, TempDir())
+	}
+	pages, err := os.MkdirTemp(TempDir(), TempPrefix+"pages-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pages, "page_0001.pdf"), []byte("%PDF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveTempRoot(); err != nil {
+		t.Fatalf("remove temp root: %v", err)
+	}
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Fatalf("expected temp root removed, got %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Fatalf("expected other files kept: %v", err)
+	}
+	if TempDir() != os.TempDir() {
+		t.Fatalf("expected os.TempDir after removal, got %s", TempDir())
+	}
+}
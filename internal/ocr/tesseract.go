@@ -0,0 +1,157 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultDPI is the resolution pages are rasterized at and pixel coordinates refer to.
+const defaultDPI = 300
+
+// Tesseract recognizes pages by rasterizing them with pdftoppm and running the tesseract CLI directly.
+type Tesseract struct {
+	Binary     string
+	Rasterizer string // pdftoppm binary (default: pdftoppm)
+	DPI        int    // Rasterization resolution (default: 300)
+	Timeout    time.Duration
+}
+
+// Recognize rasterizes a single page PDF and returns the text recognized by tesseract.
+func (t *Tesseract) Recognize(ctx context.Context, pagePath string, opts Options) (string, error) {
+	out, err := t.run(ctx, pagePath, "stdout", opts)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(normalizeNewlines(string(out))), nil
+}
+
+// RecognizeLayout rasterizes a single page PDF and returns the text with word boxes from tesseract TSV output.
+func (t *Tesseract) RecognizeLayout(ctx context.Context, pagePath string, opts Options) (string, []Line, error) {
+	out, err := t.run(ctx, pagePath, "stdout", opts, "tsv")
+	if err != nil {
+		return "", nil, err
+	}
+	lines, err := parseTSV(out, t.dpi())
+	if err != nil {
+		return "", nil, err
+	}
+	return linesText(lines), lines, nil
+}
+
+// RecognizePDF rasterizes a single page PDF, writes tesseract's searchable PDF rendering to outputPath and returns the text.
+func (t *Tesseract) RecognizePDF(ctx context.Context, pagePath, outputPath string, opts Options) (string, error) {
+	tempDir, err := os.MkdirTemp(TempDir(), "ocr-tesseract-*")
+	if err != nil {
+		return "", fmt.Errorf("create output dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	base := filepath.Join(tempDir, "page")
+	if _, err := t.run(ctx, pagePath, base, opts, "--dpi", strconv.Itoa(t.dpi()), "txt", "pdf"); err != nil {
+		return "", err
+	}
+	if err := os.Rename(base+".pdf", outputPath); err != nil {
+		return "", fmt.Errorf("move tesseract pdf: %w", err)
+	}
+
+	data, err := os.ReadFile(base + ".txt")
+	if err != nil {
+		return "", fmt.Errorf("read tesseract text: %w", err)
+	}
+	return strings.TrimSpace(normalizeNewlines(string(data))), nil
+}
+
+// DetectScript rasterizes a single page PDF and runs tesseract orientation and script detection on it.
+func (t *Tesseract) DetectScript(ctx context.Context, pagePath string) (string, error) {
+	out, err := t.run(ctx, pagePath, "stdout", Options{Language: "osd"}, "--psm", "0")
+	if err != nil {
+		return "", err
+	}
+	return parseOSD(out)
+}
+
+// run rasterizes the page and invokes tesseract with the given output base and configs, returning stdout.
+func (t *Tesseract) run(ctx context.Context, pagePath, outputBase string, opts Options, configs ...string) ([]byte, error) {
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	imagePath, cleanup, err := t.rasterize(ctx, cmdCtx, pagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	args := []string{imagePath, outputBase}
+	if opts.Language != "" {
+		args = append(args, "-l", opts.Language)
+	}
+	args = append(args, configs...)
+
+	cmd := exec.CommandContext(cmdCtx, t.binary(), args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, engineError(ctx, cmdCtx, "tesseract", err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// rasterize renders the first page of a PDF to a PNG image under cmdCtx. ctx is the caller's context.
+func (t *Tesseract) rasterize(ctx, cmdCtx context.Context, pagePath string) (string, func(), error) {
+	rasterizer := t.Rasterizer
+	if rasterizer == "" {
+		rasterizer = "pdftoppm"
+	}
+
+	tempDir, err := os.MkdirTemp(TempDir(), "ocr-raster-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("create raster dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	prefix := filepath.Join(tempDir, "page")
+	cmd := exec.CommandContext(cmdCtx, rasterizer,
+		"-r", strconv.Itoa(t.dpi()),
+		"-png",
+		"-singlefile",
+		pagePath, prefix,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return "", nil, engineError(ctx, cmdCtx, "pdftoppm", err, stderr.String())
+	}
+
+	return prefix + ".png", cleanup, nil
+}
+
+func (t *Tesseract) binary() string {
+	if t.Binary == "" {
+		return "tesseract"
+	}
+	return t.Binary
+}
+
+func (t *Tesseract) dpi() int {
+	if t.DPI <= 0 {
+		return defaultDPI
+	}
+	return t.DPI
+}
@@ -0,0 +1,126 @@
+package ocr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTesseract_Recognize(t *testing.T) {
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "tesseract.args")
+
+	// pdftoppm stand-in: writes <prefix>.png for the last argument
+	rasterizer := writeScript(t, dir, "fake-pdftoppm", `for arg in "$@"; do prefix="$arg"; done
+echo "png" > "$prefix.png"`)
+	binary := writeScript(t, dir, "fake-tesseract", `echo "$@" > `+argsLog+`
+printf 'Hello\r\nTesseract\n\n'`)
+
+	rec := &Tesseract{Binary: binary, Rasterizer: rasterizer, Timeout: 10 * time.Second}
+	text, err := rec.Recognize(context.Background(), filepath.Join(dir, "page.pdf"), Options{Language: "eng+ind"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Hello\nTesseract" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if !strings.Contains(string(args), ".png stdout -l eng+ind") {
+		t.Fatalf("unexpected tesseract args: %s", args)
+	}
+}
+
+func TestTesseract_RecognizeLayout(t *testing.T) {
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "tesseract.args")
+	tsvPath := filepath.Join(dir, "out.tsv")
+	if err := os.WriteFile(tsvPath, []byte(sampleTSV), 0o644); err != nil {
+		t.Fatalf("write tsv: %v", err)
+	}
+
+	rasterizer := writeScript(t, dir, "fake-pdftoppm", `for arg in "$@"; do prefix="$arg"; done
+echo "png" > "$prefix.png"`)
+	binary := writeScript(t, dir, "fake-tesseract", `echo "$@" > `+argsLog+`
+cat `+tsvPath)
+
+	rec := &Tesseract{Binary: binary, Rasterizer: rasterizer, DPI: 150}
+	text, lines, err := rec.RecognizeLayout(context.Background(), filepath.Join(dir, "page.pdf"), Options{Language: "eng"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Invoice No.\n42" || len(lines) != 2 {
+		t.Fatalf("unexpected layout: %q %+v", text, lines)
+	}
+	// 300px at 150dpi is two inches, i.e. 144pt
+	if lines[0].BBox.X0 != 144 {
+		t.Errorf("expected bbox scaled by dpi, got %+v", lines[0].BBox)
+	}
+
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(string(args)), "-l eng tsv") {
+		t.Fatalf("expected tsv output config, got %s", args)
+	}
+}
+
+func TestTesseract_RecognizePDF(t *testing.T) {
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "tesseract.args")
+
+	rasterizer := writeScript(t, dir, "fake-pdftoppm", `for arg in "$@"; do prefix="$arg"; done
+echo "png" > "$prefix.png"`)
+	// tesseract writes <base>.txt and <base>.pdf for the txt and pdf configs
+	binary := writeScript(t, dir, "fake-tesseract", `echo "$@" > `+argsLog+`
+printf 'Scanned text\n' > "$2.txt"
+echo "%PDF" > "$2.pdf"`)
+
+	outputPath := filepath.Join(dir, "out.pdf")
+	rec := &Tesseract{Binary: binary, Rasterizer: rasterizer}
+	text, err := rec.RecognizePDF(context.Background(), filepath.Join(dir, "page.pdf"), outputPath, Options{Language: "eng"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Scanned text" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Fatalf("expected searchable page at output path: %v", err)
+	}
+
+	args, err := os.ReadFile(argsLog)
+	if err != nil {
+		t.Fatalf("read args: %v", err)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(string(args)), "-l eng --dpi 300 txt pdf") {
+		t.Fatalf("expected txt and pdf output configs, got %s", args)
+	}
+}
+
+func TestTesseract_RasterizeError(t *testing.T) {
+	dir := t.TempDir()
+	rasterizer := writeScript(t, dir, "fake-pdftoppm", `echo "bad pdf" >&2; exit 1`)
+
+	rec := &Tesseract{Binary: "true", Rasterizer: rasterizer}
+	_, err := rec.Recognize(context.Background(), filepath.Join(dir, "page.pdf"), Options{})
+	if err == nil || !strings.Contains(err.Error(), "bad pdf") {
+		t.Fatalf("expected rasterizer error, got %v", err)
+	}
+}
+
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
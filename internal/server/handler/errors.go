@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"app/internal/ocr"
+	"app/internal/server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Machine-readable error codes returned in the "code" field of error responses.
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeInvalidOption       = "invalid_option"
+	CodeUnsupportedImage    = "unsupported_image"
+	CodeInvalidPDF          = "invalid_pdf"
+	CodePasswordRequired    = "password_required"
+	CodeWrongPassword       = "wrong_password"
+	CodePagesOutOfRange     = "pages_out_of_range"
+	CodeTooManyPages        = "too_many_pages"
+	CodeUnsupportedLanguage = "unsupported_language"
+	CodeEngineUnavailable   = "engine_unavailable"
+	CodeOCRTimeout          = "ocr_timeout"
+	CodeOCRFailed           = "ocr_failed"
+	CodeRequestCancelled    = "request_cancelled"
+	CodeJobNotFound         = "job_not_found"
+	CodeJobNotFinished      = "job_not_finished"
+	CodeJobFailed           = "job_failed"
+	CodeQueueFull           = "queue_full"
+	CodeNotFound            = "not_found"
+	CodeInvalidSchema       = "invalid_schema"
+	CodeExtractionFailed    = "extraction_failed"
+	CodeInternal            = "internal_error"
+)
+
+// StatusClientClosedRequest is reported when the client went away before OCR finished.
+const StatusClientClosedRequest = 499
+
+// ocrFailure describes how an OCR error is reported to the client.
+type ocrFailure struct {
+	status  int
+	code    string
+	message string
+}
+
+// ocrFailures maps OCR errors to responses, checked in order.
+var ocrFailures = []struct {
+	err     error
+	failure ocrFailure
+}{
+	{ocr.ErrUnsupportedImage, ocrFailure{http.StatusUnsupportedMediaType, CodeUnsupportedImage, "unsupported image type"}},
+	{ocr.ErrPasswordRequired, ocrFailure{http.StatusUnprocessableEntity, CodePasswordRequired, "pdf is encrypted, password required"}},
+	{ocr.ErrWrongPassword, ocrFailure{http.StatusUnprocessableEntity, CodeWrongPassword, "wrong pdf password"}},
+	{ocr.ErrInvalidPDF, ocrFailure{http.StatusUnprocessableEntity, CodeInvalidPDF, "invalid pdf"}},
+	{ocr.ErrPageOutOfRange, ocrFailure{http.StatusBadRequest, CodePagesOutOfRange, "pages out of range"}},
+	{ocr.ErrTooManyPages, ocrFailure{http.StatusRequestEntityTooLarge, CodeTooManyPages, "too many pages"}},
+	{ocr.ErrUnsupportedLanguage, ocrFailure{http.StatusBadRequest, CodeUnsupportedLanguage, "unsupported language"}},
+	{ocr.ErrEngineMissing, ocrFailure{http.StatusServiceUnavailable, CodeEngineUnavailable, "ocr engine unavailable"}},
+	{ocr.ErrEngineTimeout, ocrFailure{http.StatusGatewayTimeout, CodeOCRTimeout, "ocr timed out"}},
+	{context.DeadlineExceeded, ocrFailure{http.StatusGatewayTimeout, CodeOCRTimeout, "ocr timed out"}},
+	{context.Canceled, ocrFailure{StatusClientClosedRequest, CodeRequestCancelled, "request cancelled"}},
+}
+
+// classifyOCRError maps an OCR error to the status code, error code and message reported to the client.
+// Unrecognized errors are reported as engine failures.
+func classifyOCRError(err error) ocrFailure {
+	for _, f := range ocrFailures {
+		if errors.Is(err, f.err) {
+			return f.failure
+		}
+	}
+	return ocrFailure{http.StatusBadGateway, CodeOCRFailed, "ocr error"}
+}
+
+// abortWithOCRError logs err and writes the matching error response.
+func abortWithOCRError(c *gin.Context, err error) {
+	logError(c, "ocr error", err)
+	f := classifyOCRError(err)
+	c.AbortWithStatusJSON(f.status, errorBody(c, f.code, f.message))
+}
+
+// errorBody builds the JSON body of an error response.
+func errorBody(c *gin.Context, code, message string) gin.H {
+	body := gin.H{
+		"error": message,
+		"code":  code,
+	}
+	if id := middleware.RequestID(c); id != "" {
+		body["request_id"] = id
+	}
+	return body
+}
+
+// logError logs err with the request ID so it can be matched to the response, and the
+// authenticated client when there is one.
+func logError(c *gin.Context, what string, err error) {
+	id, client := middleware.RequestID(c), middleware.Client(c)
+	switch {
+	case id != "" && client != "":
+		log.Printf("%s [request %s client %s]: %v", what, id, client, err)
+	case id != "":
+		log.Printf("%s [request %s]: %v", what, id, err)
+	default:
+		log.Printf("%s: %v", what, err)
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/ocr"
+	"app/internal/server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClassifyOCRError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("persist upload: %w", ocr.ErrUnsupportedImage), http.StatusUnsupportedMediaType, CodeUnsupportedImage},
+		{fmt.Errorf("split pdf: %w", ocr.ErrInvalidPDF), http.StatusUnprocessableEntity, CodeInvalidPDF},
+		{fmt.Errorf("split pdf: %w", ocr.ErrPasswordRequired), http.StatusUnprocessableEntity, CodePasswordRequired},
+		{fmt.Errorf("split pdf: %w", ocr.ErrWrongPassword), http.StatusUnprocessableEntity, CodeWrongPassword},
+		{fmt.Errorf("split pdf: %w", ocr.ErrPageOutOfRange), http.StatusBadRequest, CodePagesOutOfRange},
+		{fmt.Errorf("split pdf: %w", ocr.ErrTooManyPages), http.StatusRequestEntityTooLarge, CodeTooManyPages},
+		{fmt.Errorf("ocr page 1: %w", ocr.ErrUnsupportedLanguage), http.StatusBadRequest, CodeUnsupportedLanguage},
+		{fmt.Errorf("ocr page 1: %w", ocr.ErrEngineMissing), http.StatusServiceUnavailable, CodeEngineUnavailable},
+		{fmt.Errorf("ocr page 1: %w", ocr.ErrEngineTimeout), http.StatusGatewayTimeout, CodeOCRTimeout},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeOCRTimeout},
+		{context.Canceled, StatusClientClosedRequest, CodeRequestCancelled},
+		{fmt.Errorf("ocr page 1: %w", ocr.ErrEngineFailed), http.StatusBadGateway, CodeOCRFailed},
+		{errors.New("boom"), http.StatusBadGateway, CodeOCRFailed},
+	}
+
+	for _, tt := range tests {
+		f := classifyOCRError(tt.err)
+		if f.status != tt.status || f.code != tt.code {
+			t.Errorf("%v: got %d %q, want %d %q", tt.err, f.status, f.code, tt.status, tt.code)
+		}
+	}
+}
+
+func TestOCRHandler_ErrorBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandler(&fakeService{err: fmt.Errorf("split pdf: %w", ocr.ErrInvalidPDF)})
+
+	r := gin.New()
+	r.Use(middleware.WithRequestID())
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 got %d", w.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["code"] != CodeInvalidPDF || body["error"] != "invalid pdf" || body["request_id"] != "req-42" {
+		t.Fatalf("unexpected error body: %v", body)
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"app/internal/extract"
+	"app/internal/ocr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldExtractor defines the field extraction behavior consumed by the handler.
+type FieldExtractor interface {
+	CheckMethod(method string) error
+	Extract(ctx context.Context, schema *extract.Schema, pages []ocr.PageContent, method string) (extract.Result, error)
+}
+
+// maxSchemaSize limits the JSON schema part of an extraction request.
+const maxSchemaSize = 64 << 10
+
+// ExtractHandler fills JSON schemas of fields from uploaded PDFs.
+type ExtractHandler struct {
+	service   OCRService
+	extractor FieldExtractor
+	cfg       Config
+}
+
+// NewExtractHandler builds the handler with the given request defaults and limits.
+func NewExtractHandler(svc OCRService, extractor FieldExtractor, cfg Config) *ExtractHandler {
+	return &ExtractHandler{service: svc, extractor: extractor, cfg: cfg}
+}
+
+// extractResponse is the extraction result with the number of pages it was read from.
+type extractResponse struct {
+	extract.Result
+	Pages int `json:"pages"`
+}
+
+// HandleExtract runs OCR on the uploaded PDF and fills the schema sent in the "schema" part.
+func (h *ExtractHandler) HandleExtract(c *gin.Context) {
+	req, ok := parseUpload(c, h.cfg)
+	if !ok {
+		return
+	}
+	defer req.file.Close()
+
+	if req.pdf {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidOption, "output=pdf is not supported for extraction"))
+		return
+	}
+	form := c.Request.MultipartForm
+	data, err := formPart(form, "schema", maxSchemaSize)
+	if err == nil && data == nil {
+		err = errors.New("missing schema")
+	}
+	var schema *extract.Schema
+	if err == nil {
+		schema, err = extract.ParseSchema(data)
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidSchema, err.Error()))
+		return
+	}
+	method := formValue(form, "method")
+	if err := h.extractor.CheckMethod(method); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidOption, err.Error()))
+		return
+	}
+
+	ctx := requestContext(c)
+	result, err := h.service.Process(ctx, req.file, req.header, req.opts)
+	if err != nil {
+		abortWithOCRError(c, err)
+		return
+	}
+	if result.Cache != "" {
+		c.Header(CacheHeader, string(result.Cache))
+	}
+
+	fields, err := h.extractor.Extract(ctx, schema, result.Pages, method)
+	if err != nil {
+		if ctx.Err() != nil {
+			abortWithOCRError(c, ctx.Err())
+			return
+		}
+		logError(c, "extract error", err)
+		c.AbortWithStatusJSON(http.StatusBadGateway, errorBody(c, CodeExtractionFailed, "field extraction failed"))
+		return
+	}
+	c.JSON(http.StatusOK, extractResponse{Result: fields, Pages: len(result.Pages)})
+}
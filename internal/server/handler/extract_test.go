@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"app/internal/extract"
+	"app/internal/ocr"
+
+	"github.com/gin-gonic/gin"
+)
+
+const extractSchema = `{
+	"type": "object",
+	"properties": {
+		"invoice_number": {"type": "string", "pattern": "^INV-\\d+$"},
+		"total": {"type": "number"}
+	},
+	"required": ["total"]
+}`
+
+type failingExtractor struct {
+	*extract.Extractor
+}
+
+func (*failingExtractor) Extract(ctx context.Context, schema *extract.Schema, pages []ocr.PageContent, method string) (extract.Result, error) {
+	return extract.Result{}, errors.New("model crashed")
+}
+
+func serveExtract(t *testing.T, svc OCRService, extractor FieldExtractor, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(w)
+	r.POST("/ocr", NewExtractHandler(svc, extractor, Config{}).HandleExtract)
+	r.ServeHTTP(w, newMultipartRequest(t, fields))
+	return w
+}
+
+func TestExtractHandler_Success(t *testing.T) {
+	svc := &fakeService{pages: []ocr.PageContent{
+		{Page: 1, Content: "Invoice INV-2041"},
+		{Page: 2, Content: "Total: 1,100.00"},
+	}}
+
+	w := serveExtract(t, svc, extract.NewExtractor(nil), map[string]string{"schema": extractSchema, "lang": "eng"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Fields map[string]extract.Value `json:"fields"`
+		Valid  bool                     `json:"valid"`
+		Pages  int                      `json:"pages"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if !body.Valid || body.Pages != 2 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	if v := body.Fields["invoice_number"]; v.Value != "INV-2041" || v.Page != 1 {
+		t.Fatalf("unexpected invoice_number: %+v", v)
+	}
+	if v := body.Fields["total"]; v.Value != 1100.0 || v.Page != 2 {
+		t.Fatalf("unexpected total: %+v", v)
+	}
+	if svc.lastOpts.Language != "eng" {
+		t.Fatalf("expected OCR options to be applied, got %+v", svc.lastOpts)
+	}
+}
+
+func TestExtractHandler_BadRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		code   string
+	}{
+		{"missing schema", map[string]string{}, CodeInvalidSchema},
+		{"invalid schema", map[string]string{"schema": `{"type": "object"}`}, CodeInvalidSchema},
+		{"unknown method", map[string]string{"schema": extractSchema, "method": "magic"}, CodeInvalidOption},
+		{"llm without model", map[string]string{"schema": extractSchema, "method": "llm"}, CodeInvalidOption},
+		{"pdf output", map[string]string{"schema": extractSchema, "output": "pdf"}, CodeInvalidOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{}
+			w := serveExtract(t, svc, extract.NewExtractor(nil), tt.fields)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":"`+tt.code+`"`) {
+				t.Fatalf("expected 400 %s, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExtractHandler_ExtractionFailed(t *testing.T) {
+	svc := &fakeService{pages: []ocr.PageContent{{Page: 1, Content: "Total: 5"}}}
+
+	w := serveExtract(t, svc, &failingExtractor{extract.NewExtractor(nil)}, map[string]string{"schema": extractSchema})
+	if w.Code != http.StatusBadGateway || !strings.Contains(w.Body.String(), CodeExtractionFailed) {
+		t.Fatalf("expected 502 extraction_failed, got %d: %s", w.Code, w.Body.String())
+	}
+}
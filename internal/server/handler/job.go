@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"app/internal/job"
+	"app/internal/ocr"
+	"app/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobService defines the asynchronous job behavior consumed by the handler.
+type JobService interface {
+	Submit(ctx context.Context, r io.Reader, req job.Request) (*job.Job, error)
+	Get(ctx context.Context, id string) (*job.Job, error)
+	Cancel(ctx context.Context, id string) (*job.Job, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// JobHandler manages asynchronous OCR job HTTP interactions.
+type JobHandler struct {
+	jobs JobService
+	cfg  Config
+}
+
+// NewJobHandler builds the handler.
+func NewJobHandler(jobs JobService) *JobHandler {
+	return &JobHandler{jobs: jobs}
+}
+
+// NewJobHandlerWithLanguages builds a handler that rejects jobs for languages that are not installed.
+func NewJobHandlerWithLanguages(jobs JobService, languages *ocr.LanguageSet) *JobHandler {
+	return &JobHandler{jobs: jobs, cfg: Config{Languages: languages}}
+}
+
+// NewJobHandlerWithConfig builds a handler with the given request defaults and limits.
+func NewJobHandlerWithConfig(jobs JobService, cfg Config) *JobHandler {
+	return &JobHandler{jobs: jobs, cfg: cfg}
+}
+
+// jobStatus is the job representation returned by the status endpoints.
+type jobStatus struct {
+	ID         string            `json:"id"`
+	Status     job.Status        `json:"status"`
+	Filename   string            `json:"filename"`
+	Progress   job.Progress      `json:"progress"`
+	Summary    *ocr.Summary      `json:"summary,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Callback   string            `json:"callback_url,omitempty"`
+	Searchable bool              `json:"searchable_pdf,omitempty"`
+	Deliveries []webhook.Attempt `json:"deliveries,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+func newJobStatus(j *job.Job) jobStatus {
+	status := jobStatus{
+		ID:         j.ID,
+		Status:     j.Status,
+		Filename:   j.Filename,
+		Progress:   j.Progress,
+		Summary:    j.Summary,
+		Error:      j.Error,
+		Searchable: j.OutputPath != "",
+		Deliveries: j.Deliveries,
+		CreatedAt:  j.CreatedAt,
+		UpdatedAt:  j.UpdatedAt,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+	}
+	// Never echo the callback secret
+	if j.Callback != nil {
+		status.Callback = j.Callback.URL
+	}
+	return status
+}
+
+// HandleSubmit queues a PDF for asynchronous OCR and returns the job ID.
+// An optional callback_url (and callback_secret) is notified when the job finishes.
+// With output=pdf a searchable PDF is produced alongside the pages.
+func (h *JobHandler) HandleSubmit(c *gin.Context) {
+	req, ok := parseUpload(c, h.cfg)
+	if !ok {
+		return
+	}
+	defer req.file.Close()
+
+	var callback *job.Callback
+	if callbackURL := c.Request.FormValue("callback_url"); callbackURL != "" {
+		if err := webhook.ValidateURL(callbackURL); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidOption, "invalid callback_url"))
+			return
+		}
+		callback = &job.Callback{
+			URL:    callbackURL,
+			Secret: c.Request.FormValue("callback_secret"),
+		}
+	}
+
+	j, err := h.jobs.Submit(c.Request.Context(), req.file, job.Request{
+		Filename:      req.header.Filename,
+		Options:       req.opts,
+		Callback:      callback,
+		SearchablePDF: req.pdf,
+	})
+	if err != nil {
+		if errors.Is(err, webhook.ErrNonPublicAddress) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidOption, "callback_url must resolve to a public address"))
+			return
+		}
+		if errors.Is(err, webhook.ErrInvalidURL) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidOption, "invalid callback_url"))
+			return
+		}
+		if errors.Is(err, job.ErrQueueFull) {
+			logError(c, "job submit error", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, errorBody(c, CodeQueueFull, "job queue is full"))
+			return
+		}
+		if errors.Is(err, ocr.ErrPasswordRequired) || errors.Is(err, ocr.ErrWrongPassword) || errors.Is(err, ocr.ErrInvalidPDF) {
+			abortWithOCRError(c, err)
+			return
+		}
+		logError(c, "job submit error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorBody(c, CodeInternal, "job error"))
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"/"+j.ID)
+	c.JSON(http.StatusAccepted, newJobStatus(j))
+}
+
+// HandleStatus returns the status and per-page progress of a job.
+func (h *JobHandler) HandleStatus(c *gin.Context) {
+	j, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, newJobStatus(j))
+}
+
+// HandleResult returns the pages of a succeeded job.
+func (h *JobHandler) HandleResult(c *gin.Context) {
+	j, ok := h.getSucceededJob(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, j.Pages)
+}
+
+// HandlePDF downloads the searchable PDF of a succeeded job submitted with output=pdf.
+func (h *JobHandler) HandlePDF(c *gin.Context) {
+	j, ok := h.getSucceededJob(c)
+	if !ok {
+		return
+	}
+	if j.OutputPath == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, errorBody(c, CodeNotFound, "searchable pdf not requested"))
+		return
+	}
+	c.FileAttachment(j.OutputPath, searchablePDFName(j.Filename))
+}
+
+// HandleCancel cancels a queued or running job, or removes a finished one.
+func (h *JobHandler) HandleCancel(c *gin.Context) {
+	id := c.Param("id")
+
+	j, err := h.jobs.Cancel(c.Request.Context(), id)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, newJobStatus(j))
+		return
+	case errors.Is(err, job.ErrFinished):
+		err = h.jobs.Delete(c.Request.Context(), id)
+		if err == nil {
+			c.Status(http.StatusNoContent)
+			return
+		}
+	}
+
+	h.abortWithJobError(c, err)
+}
+
+// getJob loads the job named by the id path parameter, writing an error response if it fails.
+func (h *JobHandler) getJob(c *gin.Context) (*job.Job, bool) {
+	j, err := h.jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.abortWithJobError(c, err)
+		return nil, false
+	}
+	return j, true
+}
+
+// getSucceededJob loads the job like getJob and writes a 409 unless it succeeded.
+func (h *JobHandler) getSucceededJob(c *gin.Context) (*job.Job, bool) {
+	j, ok := h.getJob(c)
+	if !ok {
+		return nil, false
+	}
+
+	if j.Status != job.StatusSucceeded {
+		body := errorBody(c, CodeJobNotFinished, "job not finished")
+		if j.Status.Finished() {
+			body = errorBody(c, CodeJobFailed, "job did not succeed")
+		}
+		body["status"] = j.Status
+		c.AbortWithStatusJSON(http.StatusConflict, body)
+		return nil, false
+	}
+	return j, true
+}
+
+func (h *JobHandler) abortWithJobError(c *gin.Context, err error) {
+	if errors.Is(err, job.ErrNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, errorBody(c, CodeJobNotFound, "job not found"))
+		return
+	}
+	logError(c, "job error", err)
+	c.AbortWithStatusJSON(http.StatusInternalServerError, errorBody(c, CodeInternal, "job error"))
+}
@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"app/internal/job"
+	"app/internal/ocr"
+	"app/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeJobService struct {
+	jobs      map[string]*job.Job
+	submitErr error
+	cancelErr error
+	deleted   []string
+	lastReq   job.Request
+}
+
+func (f *fakeJobService) Submit(ctx context.Context, r io.Reader, req job.Request) (*job.Job, error) {
+	if f.submitErr != nil {
+		return nil, f.submitErr
+	}
+	f.lastReq = req
+	j := &job.Job{ID: "job-1", Status: job.StatusQueued, Filename: req.Filename, Callback: req.Callback}
+	f.jobs = map[string]*job.Job{j.ID: j}
+	return j, nil
+}
+
+func (f *fakeJobService) Get(ctx context.Context, id string) (*job.Job, error) {
+	j, ok := f.jobs[id]
+	if !ok {
+		return nil, job.ErrNotFound
+	}
+	return j, nil
+}
+
+func (f *fakeJobService) Cancel(ctx context.Context, id string) (*job.Job, error) {
+	j, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if f.cancelErr != nil {
+		return nil, f.cancelErr
+	}
+	j.Status = job.StatusCancelled
+	return j, nil
+}
+
+func (f *fakeJobService) Delete(ctx context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func newJobRouter(svc JobService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewJobHandler(svc)
+	r := gin.New()
+	r.POST("/jobs", h.HandleSubmit)
+	r.GET("/jobs/:id", h.HandleStatus)
+	r.GET("/jobs/:id/result", h.HandleResult)
+	r.GET("/jobs/:id/pdf", h.HandlePDF)
+	r.DELETE("/jobs/:id", h.HandleCancel)
+	return r
+}
+
+func TestJobHandler_Submit(t *testing.T) {
+	svc := &fakeJobService{}
+	r := newJobRouter(svc)
+
+	req := newMultipartRequest(t, map[string]string{"lang": "ind", "output": "layout"})
+	req.URL.Path = "/jobs"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/jobs/job-1" {
+		t.Fatalf("unexpected location: %s", loc)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["id"] != "job-1" || body["status"] != "queued" {
+		t.Fatalf("unexpected body: %v", body)
+	}
+	if svc.lastReq.Options.Language != "ind" || !svc.lastReq.Options.Layout || svc.lastReq.SearchablePDF {
+		t.Fatalf("expected options to pass through, got %+v", svc.lastReq)
+	}
+}
+
+func TestJobHandler_SubmitWithCallback(t *testing.T) {
+	svc := &fakeJobService{}
+	r := newJobRouter(svc)
+
+	req := newMultipartRequest(t, map[string]string{
+		"callback_url":    "https://example.com/hooks/ocr",
+		"callback_secret": "shh",
+	})
+	req.URL.Path = "/jobs"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", w.Code)
+	}
+	cb := svc.jobs["job-1"].Callback
+	if cb == nil || cb.URL != "https://example.com/hooks/ocr" || cb.Secret != "shh" {
+		t.Fatalf("unexpected callback: %+v", cb)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"callback_url":"https://example.com/hooks/ocr"`) {
+		t.Fatalf("expected callback url in body: %s", body)
+	}
+	if strings.Contains(body, "shh") {
+		t.Fatalf("callback secret must not be echoed: %s", body)
+	}
+}
+
+func TestJobHandler_SubmitInvalidCallback(t *testing.T) {
+	for _, callbackURL := range []string{"ftp://example.com", "/relative", "not a url"} {
+		r := newJobRouter(&fakeJobService{})
+
+		req := newMultipartRequest(t, map[string]string{"callback_url": callbackURL})
+		req.URL.Path = "/jobs"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400 got %d", callbackURL, w.Code)
+		}
+	}
+}
+
+func TestJobHandler_SubmitNonPublicCallback(t *testing.T) {
+	r := newJobRouter(&fakeJobService{submitErr: fmt.Errorf("%w: 127.0.0.1", webhook.ErrNonPublicAddress)})
+
+	req := newMultipartRequest(t, map[string]string{"callback_url": "http://127.0.0.1/hook"})
+	req.URL.Path = "/jobs"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "public address") {
+		t.Fatalf("expected 400 for a loopback callback, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJobHandler_SubmitQueueFull(t *testing.T) {
+	r := newJobRouter(&fakeJobService{submitErr: job.ErrQueueFull})
+
+	req := newMultipartRequest(t, nil)
+	req.URL.Path = "/jobs"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 got %d", w.Code)
+	}
+}
+
+func TestJobHandler_SubmitWrongPassword(t *testing.T) {
+	r := newJobRouter(&fakeJobService{submitErr: ocr.ErrWrongPassword})
+
+	req := newMultipartRequest(t, map[string]string{"password": "guess"})
+	req.URL.Path = "/jobs"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 got %d", w.Code)
+	}
+}
+
+func TestJobHandler_SubmitMissingFile(t *testing.T) {
+	r := newJobRouter(&fakeJobService{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader("invalid")))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", w.Code)
+	}
+}
+
+func TestJobHandler_Status(t *testing.T) {
+	svc := &fakeJobService{jobs: map[string]*job.Job{
+		"job-1": {
+			ID:       "job-1",
+			Status:   job.StatusRunning,
+			Progress: job.Progress{PagesDone: 1, PagesTotal: 3, CompletedPages: []int{2}},
+			Pages:    []ocr.PageContent{{Page: 2, Content: "secret page"}},
+		},
+	}}
+	r := newJobRouter(svc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/job-1", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `"pages_done":1`) || !strings.Contains(body, `"pages_total":3`) {
+		t.Fatalf("expected progress in body: %s", body)
+	}
+	if strings.Contains(body, "secret page") {
+		t.Fatalf("status must not include page content: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 got %d", w.Code)
+	}
+}
+
+func TestJobHandler_Result(t *testing.T) {
+	svc := &fakeJobService{jobs: map[string]*job.Job{
+		"done":    {ID: "done", Status: job.StatusSucceeded, Pages: []ocr.PageContent{{Page: 1, Content: handlerExpectedText}}},
+		"running": {ID: "running", Status: job.StatusRunning},
+		"failed":  {ID: "failed", Status: job.StatusFailed, Error: "boom"},
+	}}
+	r := newJobRouter(svc)
+
+	tests := []struct {
+		id       string
+		code     int
+		contains string
+	}{
+		{"done", http.StatusOK, handlerExpectedText},
+		{"running", http.StatusConflict, "job not finished"},
+		{"failed", http.StatusConflict, "job did not succeed"},
+		{"missing", http.StatusNotFound, "job not found"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+tt.id+"/result", nil))
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("%s: got %d %s", tt.id, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestJobHandler_SubmitSearchablePDF(t *testing.T) {
+	svc := &fakeJobService{}
+	r := newJobRouter(svc)
+
+	req := newMultipartRequest(t, map[string]string{"output": "pdf"})
+	req.URL.Path = "/jobs"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 got %d", w.Code)
+	}
+	if !svc.lastReq.SearchablePDF {
+		t.Fatalf("expected searchable pdf to be requested, got %+v", svc.lastReq)
+	}
+}
+
+func TestJobHandler_PDF(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "job.ocr.pdf")
+	if err := os.WriteFile(outputPath, []byte("%PDF-1.7 searchable"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	svc := &fakeJobService{jobs: map[string]*job.Job{
+		"done":    {ID: "done", Status: job.StatusSucceeded, Filename: "scan.pdf", OutputPath: outputPath},
+		"text":    {ID: "text", Status: job.StatusSucceeded},
+		"running": {ID: "running", Status: job.StatusRunning, OutputPath: outputPath},
+	}}
+	r := newJobRouter(svc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/done/pdf", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "%PDF-1.7 searchable" {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "scan-ocr.pdf") {
+		t.Fatalf("unexpected content disposition: %s", cd)
+	}
+
+	tests := []struct {
+		id   string
+		code int
+	}{
+		{"text", http.StatusNotFound},
+		{"running", http.StatusConflict},
+		{"missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/"+tt.id+"/pdf", nil))
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d got %d", tt.id, tt.code, w.Code)
+		}
+	}
+}
+
+func TestJobHandler_Cancel(t *testing.T) {
+	svc := &fakeJobService{jobs: map[string]*job.Job{"job-1": {ID: "job-1", Status: job.StatusRunning}}}
+	r := newJobRouter(svc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/jobs/job-1", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"cancelled"`) {
+		t.Fatalf("unexpected response: %d %s", w.Code, w.Body.String())
+	}
+	if len(svc.deleted) != 0 {
+		t.Fatalf("running job must not be deleted: %v", svc.deleted)
+	}
+}
+
+func TestJobHandler_CancelFinishedDeletes(t *testing.T) {
+	svc := &fakeJobService{
+		jobs:      map[string]*job.Job{"job-1": {ID: "job-1", Status: job.StatusSucceeded}},
+		cancelErr: job.ErrFinished,
+	}
+	r := newJobRouter(svc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/jobs/job-1", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d", w.Code)
+	}
+	if len(svc.deleted) != 1 || svc.deleted[0] != "job-1" {
+		t.Fatalf("expected finished job deleted, got %v", svc.deleted)
+	}
+}
+
+func TestJobHandler_CancelError(t *testing.T) {
+	svc := &fakeJobService{
+		jobs:      map[string]*job.Job{"job-1": {ID: "job-1"}},
+		cancelErr: errors.New("store down"),
+	}
+	r := newJobRouter(svc)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/jobs/job-1", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 got %d", w.Code)
+	}
+}
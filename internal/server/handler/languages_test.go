@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/ocr"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOCRHandler_Languages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandlerWithLanguages(&fakeService{}, ocr.NewLanguageSet([]string{"ind", "eng"}))
+
+	r := gin.New()
+	r.GET("/languages", handler.HandleLanguages)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/languages", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	var body struct {
+		Languages []string `json:"languages"`
+		Default   string   `json:"default"`
+		Auto      bool     `json:"auto"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Languages) != 2 || body.Languages[0] != "eng" || body.Default != DefaultLanguage || body.Auto {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestOCRHandler_LanguagesUnknown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/languages", NewOCRHandler(&fakeService{}).HandleLanguages)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/languages", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 got %d", w.Code)
+	}
+}
+
+func TestOCRHandler_ValidatesLanguages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		fields map[string]string
+		status int
+	}{
+		{map[string]string{"lang": "eng+ind"}, http.StatusOK},
+		{map[string]string{"lang": "eng+fra"}, http.StatusBadRequest},
+		{map[string]string{"options": `{"lang":"deu"}`}, http.StatusBadRequest},
+		// The default is not checked; it may fail later if a pack is missing
+		{nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeService{}
+		handler := NewOCRHandlerWithLanguages(svc, ocr.NewLanguageSet([]string{"eng", "ind"}))
+
+		r := gin.New()
+		r.POST("/ocr", handler.HandleOCR)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, newMultipartRequest(t, tt.fields))
+
+		if w.Code != tt.status {
+			t.Fatalf("%v: expected %d got %d", tt.fields, tt.status, w.Code)
+		}
+		if tt.status == http.StatusBadRequest {
+			var body map[string]string
+			json.Unmarshal(w.Body.Bytes(), &body)
+			if body["code"] != CodeUnsupportedLanguage {
+				t.Fatalf("%v: unexpected error body %v", tt.fields, body)
+			}
+		}
+	}
+}
+
+func TestOCRHandler_AutoLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		installed []string
+		status    int
+	}{
+		{[]string{"eng", "ind", "osd"}, http.StatusOK},
+		{[]string{"eng", "ind"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		svc := &fakeService{}
+		handler := NewOCRHandlerWithLanguages(svc, ocr.NewLanguageSet(tt.installed))
+
+		r := gin.New()
+		r.POST("/ocr", handler.HandleOCR)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, newMultipartRequest(t, map[string]string{"lang": "auto"}))
+
+		if w.Code != tt.status {
+			t.Fatalf("%v: expected %d got %d: %s", tt.installed, tt.status, w.Code, w.Body.String())
+		}
+		if tt.status == http.StatusOK && svc.lastOpts.Language != ocr.LanguageAuto {
+			t.Fatalf("expected auto language, got %q", svc.lastOpts.Language)
+		}
+	}
+}
+
+func TestOCRHandler_ConfigDefaultLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{}
+	handler := NewOCRHandlerWithConfig(svc, Config{
+		Languages:       ocr.NewLanguageSet([]string{"eng", "ind"}),
+		DefaultLanguage: "ind",
+	})
+
+	r := gin.New()
+	r.POST("/ocr", handler.HandleOCR)
+	r.GET("/languages", handler.HandleLanguages)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newMultipartRequest(t, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+	if svc.lastOpts.Language != "ind" {
+		t.Fatalf("expected configured default language, got %q", svc.lastOpts.Language)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/languages", nil))
+	var body struct {
+		Default string `json:"default"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Default != "ind" {
+		t.Fatalf("expected configured default in languages, got %q", body.Default)
+	}
+}
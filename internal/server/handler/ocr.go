@@ -2,23 +2,34 @@ package handler
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"mime/multipart"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"app/internal/metrics"
 	"app/internal/ocr"
+	"app/internal/server/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 // OCRService defines the behavior consumed by the handler.
 type OCRService interface {
-	Process(ctx context.Context, file multipart.File, header *multipart.FileHeader, lang string) ([]ocr.PageContent, error)
+	Process(ctx context.Context, file multipart.File, header *multipart.FileHeader, opts ocr.Options) (service.Result, error)
+	ProcessImage(ctx context.Context, file multipart.File, header *multipart.FileHeader, opts ocr.Options) (service.Result, error)
 }
 
+// CacheHeader reports whether the response was served from the result cache.
+const CacheHeader = "X-OCR-Cache"
+
 // OCRHandler manages OCR HTTP interactions.
 type OCRHandler struct {
 	service OCRService
+	cfg     Config
 }
 
 // NewOCRHandler builds the handler.
@@ -26,41 +37,166 @@ func NewOCRHandler(svc OCRService) *OCRHandler {
 	return &OCRHandler{service: svc}
 }
 
+// NewOCRHandlerWithLanguages builds a handler that lists the installed languages and
+// rejects requests for any other language before OCR starts.
+func NewOCRHandlerWithLanguages(svc OCRService, languages *ocr.LanguageSet) *OCRHandler {
+	return &OCRHandler{service: svc, cfg: Config{Languages: languages}}
+}
+
+// NewOCRHandlerWithConfig builds a handler with the given request defaults and limits.
+func NewOCRHandlerWithConfig(svc OCRService, cfg Config) *OCRHandler {
+	return &OCRHandler{service: svc, cfg: cfg}
+}
+
 // HandleOCR processes OCR requests for PDF files.
 func (h *OCRHandler) HandleOCR(c *gin.Context) {
-	// Parse multipart form (50MB limit)
-	if err := c.Request.ParseMultipartForm(100 << 20); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid multipart payload",
-		})
+	h.handleUpload(c, h.service.Process)
+}
+
+// HandleImage processes OCR requests for PNG, JPEG, TIFF and WebP images.
+func (h *OCRHandler) HandleImage(c *gin.Context) {
+	h.handleUpload(c, h.service.ProcessImage)
+}
+
+// HandleLanguages lists the installed OCR languages.
+func (h *OCRHandler) HandleLanguages(c *gin.Context) {
+	languages := h.cfg.Languages
+	if languages == nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, errorBody(c, CodeEngineUnavailable, "installed languages unknown"))
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{
+		"languages": languages.Codes(),
+		"default":   h.cfg.defaultLanguage(),
+		"auto":      languages.SupportsAuto(),
+	})
+}
 
-	// Get the uploaded file
-	file, header, err := c.Request.FormFile("file")
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "missing file",
-		})
+type processFunc func(ctx context.Context, file multipart.File, header *multipart.FileHeader, opts ocr.Options) (service.Result, error)
+
+// upload is a parsed OCR request.
+type upload struct {
+	file    multipart.File
+	header  *multipart.FileHeader
+	opts    ocr.Options
+	pdf     bool // Respond with a searchable PDF instead of JSON pages
+	summary bool // Wrap the pages in a pagesResponse with a document summary
+}
+
+// pagesResponse is returned instead of the bare page array when a summary is requested.
+type pagesResponse struct {
+	Pages   []ocr.PageContent `json:"pages"`
+	Summary ocr.Summary       `json:"summary"`
+}
+
+// handleUpload parses the multipart upload and runs it through process.
+func (h *OCRHandler) handleUpload(c *gin.Context, process processFunc) {
+	req, ok := parseUpload(c, h.cfg)
+	if !ok {
 		return
 	}
-	defer file.Close()
+	defer req.file.Close()
 
-	// Get language parameter (default: eng+chi_sim+ind)
-	lang := c.Request.FormValue("lang")
-	if lang == "" {
-		lang = "eng+chi_sim+ind"
+	if req.pdf {
+		h.respondPDF(c, process, req)
+		return
+	}
+	if format := streamFormat(c); format != "" {
+		h.respondStream(c, process, req, format)
+		return
 	}
 
 	// Process the OCR request
-	pages, err := h.service.Process(c.Request.Context(), file, header, lang)
+	result, err := process(requestContext(c), req.file, req.header, req.opts)
 	if err != nil {
-		log.Printf("ocr error: %v", err)
-		c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{
-			"error": "ocr error",
-		})
+		abortWithOCRError(c, err)
 		return
 	}
+	pages := result.Pages
+	if result.Cache != "" {
+		c.Header(CacheHeader, string(result.Cache))
+	}
 
+	if req.summary {
+		c.JSON(http.StatusOK, pagesResponse{Pages: pages, Summary: ocr.Summarize(pages)})
+		return
+	}
 	c.JSON(http.StatusOK, pages)
 }
+
+// respondPDF runs OCR into a temporary searchable PDF and streams it back.
+func (h *OCRHandler) respondPDF(c *gin.Context, process processFunc, req upload) {
+	output, err := os.CreateTemp(ocr.TempDir(), "ocr-searchable-*.pdf")
+	if err != nil {
+		abortWithOCRError(c, fmt.Errorf("create searchable pdf: %w", err))
+		return
+	}
+	output.Close()
+	defer os.Remove(output.Name())
+
+	req.opts.SearchablePDF = output.Name()
+	if _, err := process(requestContext(c), req.file, req.header, req.opts); err != nil {
+		abortWithOCRError(c, err)
+		return
+	}
+
+	c.FileAttachment(output.Name(), searchablePDFName(req.header.Filename))
+}
+
+// requestContext returns the request context, bypassing the result cache when the
+// client sent Cache-Control: no-cache.
+func requestContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	for _, directive := range strings.Split(c.GetHeader("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return service.WithoutCache(ctx)
+		}
+	}
+	return ctx
+}
+
+// searchablePDFName derives the download name of a searchable PDF from the uploaded file name.
+func searchablePDFName(filename string) string {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	if base == "" || base == "." || base == string(filepath.Separator) {
+		base = "document"
+	}
+	return base + "-ocr.pdf"
+}
+
+// parseUpload reads the uploaded file and OCR options from a multipart form, applying
+// the defaults and language checks of cfg.
+// On failure it writes the error response and returns ok=false.
+func parseUpload(c *gin.Context, cfg Config) (upload, bool) {
+	// Parse multipart form, spilling to temp files beyond the memory limit
+	if err := c.Request.ParseMultipartForm(cfg.multipartMemory()); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidRequest, "invalid multipart payload"))
+		return upload{}, false
+	}
+
+	// Get the uploaded file
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, CodeInvalidRequest, "missing file"))
+		return upload{}, false
+	}
+	metrics.UploadSize.Observe(float64(header.Size), c.FullPath())
+
+	// Get OCR options from form fields or a JSON options part
+	req := upload{file: file, header: header}
+	opts, err := parseOptions(c.Request.MultipartForm)
+	if err == nil {
+		err = opts.apply(&req, cfg)
+	}
+	if err != nil {
+		file.Close()
+		code := CodeInvalidOption
+		if errors.Is(err, ocr.ErrUnsupportedLanguage) {
+			code = CodeUnsupportedLanguage
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorBody(c, code, err.Error()))
+		return upload{}, false
+	}
+
+	return req, true
+}
@@ -3,18 +3,23 @@ package handler
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
 
 	"app/internal/ocr"
+	"app/internal/server/service"
+	"app/pkg"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,15 +27,42 @@ import (
 const handlerExpectedText = "content in page 1"
 
 type fakeService struct {
-	pages []ocr.PageContent
-	err   error
+	pages       []ocr.PageContent
+	err         error
+	imageCalled bool
+	lastOpts    ocr.Options
+	pdf         string // Written to Options.SearchablePDF when requested
+	cache       service.CacheStatus
+	bypassed    bool
+	midStream   bool // Report page progress before failing with err
 }
 
-func (f *fakeService) Process(ctx context.Context, file multipart.File, header *multipart.FileHeader, lang string) ([]ocr.PageContent, error) {
+func (f *fakeService) Process(ctx context.Context, file multipart.File, header *multipart.FileHeader, opts ocr.Options) (service.Result, error) {
+	f.lastOpts = opts
+	f.bypassed = service.CacheBypassed(ctx)
+	if f.cache != "" {
+		service.ReportCacheStatus(ctx, f.cache)
+	}
+	if opts.OnProgress != nil && (f.err == nil || f.midStream) {
+		opts.OnProgress(ocr.Progress{Total: len(f.pages)})
+		for i := range f.pages {
+			opts.OnProgress(ocr.Progress{Done: i + 1, Total: len(f.pages), Page: &f.pages[i]})
+		}
+	}
 	if f.err != nil {
-		return nil, f.err
+		return service.Result{}, f.err
+	}
+	if opts.SearchablePDF != "" {
+		if err := os.WriteFile(opts.SearchablePDF, []byte(f.pdf), 0o644); err != nil {
+			return service.Result{}, err
+		}
 	}
-	return f.pages, nil
+	return service.Result{Pages: f.pages, Cache: f.cache}, nil
+}
+
+func (f *fakeService) ProcessImage(ctx context.Context, file multipart.File, header *multipart.FileHeader, opts ocr.Options) (service.Result, error) {
+	f.imageCalled = true
+	return f.Process(ctx, file, header, opts)
 }
 
 func TestOCRHandler_Success(t *testing.T) {
@@ -59,6 +91,49 @@ func TestOCRHandler_Success(t *testing.T) {
 	}
 }
 
+func TestOCRHandler_CacheHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		cacheControl string
+		status       service.CacheStatus
+		bypassed     bool
+	}{
+		{name: "hit", status: service.CacheHit},
+		{name: "no-cache bypasses", cacheControl: "max-age=0, No-Cache", status: service.CacheBypass, bypassed: true},
+		{name: "no cache configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{pages: []ocr.PageContent{{Page: 1, Content: handlerExpectedText}}, cache: tt.status}
+			handler := NewOCRHandler(svc)
+
+			w := httptest.NewRecorder()
+			c, r := gin.CreateTestContext(w)
+			r.POST("/ocr", handler.HandleOCR)
+
+			req := newMultipartRequest(t, nil)
+			if tt.cacheControl != "" {
+				req.Header.Set("Cache-Control", tt.cacheControl)
+			}
+			c.Request = req
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200 got %d", w.Code)
+			}
+			if got := w.Header().Get(CacheHeader); got != string(tt.status) {
+				t.Errorf("expected %s %q, got %q", CacheHeader, tt.status, got)
+			}
+			if svc.bypassed != tt.bypassed {
+				t.Errorf("expected bypassed=%v, got %v", tt.bypassed, svc.bypassed)
+			}
+		})
+	}
+}
+
 func TestOCRHandler_MethodNotAllowed(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -142,6 +217,385 @@ func TestOCRHandler_ServiceError(t *testing.T) {
 	}
 }
 
+func TestOCRHandler_Options(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		fields   map[string]string
+		expected ocr.Options
+	}{
+		{"defaults", nil, ocr.Options{Language: "eng+chi_sim+ind", RemoveWatermark: true, Normalization: pkg.NormalizationCollapsed}},
+		{"language", map[string]string{"lang": "ind"}, ocr.Options{Language: "ind", RemoveWatermark: true, Normalization: pkg.NormalizationCollapsed}},
+		{"text output", map[string]string{"output": "text"}, ocr.Options{Language: "eng+chi_sim+ind", RemoveWatermark: true, Normalization: pkg.NormalizationCollapsed}},
+		{"layout output", map[string]string{"output": "layout"}, ocr.Options{Language: "eng+chi_sim+ind", RemoveWatermark: true, Layout: true, Normalization: pkg.NormalizationCollapsed}},
+		{"normalization", map[string]string{"normalize": "paragraphs"}, ocr.Options{Language: "eng+chi_sim+ind", RemoveWatermark: true, Normalization: pkg.NormalizationParagraphs}},
+		{"text threshold", map[string]string{"text_threshold": "40"}, ocr.Options{Language: "eng+chi_sim+ind", RemoveWatermark: true, TextThreshold: 40, Normalization: pkg.NormalizationCollapsed}},
+		{"force ocr", map[string]string{"force_ocr": "true", "remove_watermark": "1"}, ocr.Options{Language: "eng+chi_sim+ind", ForceOCR: true, RemoveWatermark: true, Normalization: pkg.NormalizationCollapsed}},
+		{"pages", map[string]string{"pages": "1-3,7,last"}, ocr.Options{Language: "eng+chi_sim+ind", RemoveWatermark: true, Pages: "1-3,7,last", Normalization: pkg.NormalizationCollapsed}},
+		{"password", map[string]string{"password": "secret"}, ocr.Options{Language: "eng+chi_sim+ind", RemoveWatermark: true, Password: "secret", Normalization: pkg.NormalizationCollapsed}},
+		{"json options", map[string]string{"options": `{"lang":"eng","text_threshold":10,"force_ocr":true,"normalize":"lines"}`}, ocr.Options{Language: "eng", TextThreshold: 10, ForceOCR: true, Normalization: pkg.NormalizationLines}},
+		{"keep watermarks", map[string]string{"remove_watermark": "false"}, ocr.Options{Language: "eng+chi_sim+ind", Normalization: pkg.NormalizationCollapsed}},
+		{"fields override json", map[string]string{"options": `{"lang":"eng","force_ocr":true}`, "lang": "ind", "force_ocr": "false"}, ocr.Options{Language: "ind", RemoveWatermark: true, Normalization: pkg.NormalizationCollapsed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{}
+			handler := NewOCRHandler(svc)
+
+			w := httptest.NewRecorder()
+			c, r := gin.CreateTestContext(w)
+			r.POST("/ocr", handler.HandleOCR)
+
+			req := newMultipartRequest(t, tt.fields)
+			c.Request = req
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200 got %d", w.Code)
+			}
+			if !reflect.DeepEqual(svc.lastOpts, tt.expected) {
+				t.Fatalf("unexpected options: %+v", svc.lastOpts)
+			}
+		})
+	}
+}
+
+func TestOCRHandler_Summary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{pages: []ocr.PageContent{
+		{Page: 1, Content: handlerExpectedText, Source: ocr.SourceOCR, Chars: 17, Language: "eng"},
+		{Page: 2, Content: "text layer", Source: ocr.SourceTextLayer, Chars: 10},
+	}}
+	handler := NewOCRHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, map[string]string{"summary": "true"})
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	var body pagesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Pages) != 2 || body.Pages[0].Source != ocr.SourceOCR {
+		t.Fatalf("unexpected pages: %+v", body.Pages)
+	}
+	if body.Summary.OCRPages != 1 || body.Summary.TextLayerPages != 1 || body.Summary.Chars != 27 {
+		t.Fatalf("unexpected summary: %+v", body.Summary)
+	}
+}
+
+func TestOCRHandler_InvalidNormalization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandler(&fakeService{})
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, map[string]string{"normalize": "markdown"})
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", w.Code)
+	}
+}
+
+func TestOCRHandler_InvalidSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandler(&fakeService{})
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, map[string]string{"summary": "maybe"})
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", w.Code)
+	}
+}
+
+func TestOCRHandler_InvalidOutput(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandler(&fakeService{})
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, map[string]string{"output": "xml"})
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", w.Code)
+	}
+}
+
+func TestOCRHandler_InvalidOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+	}{
+		{"negative threshold", map[string]string{"text_threshold": "-1"}},
+		{"threshold too large", map[string]string{"text_threshold": "100001"}},
+		{"non-numeric threshold", map[string]string{"text_threshold": "lots"}},
+		{"invalid force_ocr", map[string]string{"force_ocr": "always"}},
+		{"invalid remove_watermark", map[string]string{"remove_watermark": "yes please"}},
+		{"invalid lang", map[string]string{"lang": "eng;rm -rf"}},
+		{"invalid pages", map[string]string{"pages": "3-1"}},
+		{"invalid json pages", map[string]string{"options": `{"pages":"first"}`}},
+		{"malformed json", map[string]string{"options": `{"lang":`}},
+		{"unknown json option", map[string]string{"options": `{"concurrency":64}`}},
+		{"wrong json type", map[string]string{"options": `{"force_ocr":"true"}`}},
+		{"json threshold out of range", map[string]string{"options": `{"text_threshold":-5}`}},
+		{"correct without llm", map[string]string{"correct": "true"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeService{}
+			handler := NewOCRHandler(svc)
+
+			w := httptest.NewRecorder()
+			c, r := gin.CreateTestContext(w)
+			r.POST("/ocr", handler.HandleOCR)
+
+			req := newMultipartRequest(t, tt.fields)
+			c.Request = req
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400 got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestOCRHandler_Correct(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{}
+	handler := NewOCRHandlerWithConfig(svc, Config{Correction: true})
+
+	r := gin.New()
+	r.POST("/ocr", handler.HandleOCR)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newMultipartRequest(t, map[string]string{"options": `{"correct":true}`}))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+	if !svc.lastOpts.Correct {
+		t.Fatalf("expected correct option to pass through, got %+v", svc.lastOpts)
+	}
+}
+
+func TestOCRHandler_OptionsFilePart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{}
+	handler := NewOCRHandler(svc)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("options", "options.json")
+	if err != nil {
+		t.Fatalf("create options part: %v", err)
+	}
+	part.Write([]byte(`{"lang":"ind","text_threshold":0,"remove_watermark":true}`))
+	part, err = writer.CreateFormFile("file", "test.pdf")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	writeSamplePDF(t, part)
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := httptest.NewRequest(http.MethodPost, "/ocr", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	expected := ocr.Options{Language: "ind", RemoveWatermark: true, Normalization: pkg.NormalizationCollapsed}
+	if !reflect.DeepEqual(svc.lastOpts, expected) {
+		t.Fatalf("unexpected options: %+v", svc.lastOpts)
+	}
+}
+
+func TestOCRHandler_SearchablePDF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{pdf: "%PDF-1.7 searchable"}
+	handler := NewOCRHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, map[string]string{"output": "pdf"})
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Fatalf("expected pdf content type got %s", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "pdf-example-ocr.pdf") {
+		t.Fatalf("unexpected content disposition: %s", cd)
+	}
+	if body := w.Body.String(); body != svc.pdf {
+		t.Fatalf("unexpected body: %q", body)
+	}
+	if _, err := os.Stat(svc.lastOpts.SearchablePDF); !os.IsNotExist(err) {
+		t.Fatalf("expected temporary pdf to be removed, got %v", err)
+	}
+}
+
+func TestOCRHandler_SearchablePDFError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandler(&fakeService{err: errors.New("boom")})
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, map[string]string{"output": "pdf"})
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 got %d", w.Code)
+	}
+}
+
+func TestSearchablePDFName(t *testing.T) {
+	tests := map[string]string{
+		"scan.pdf":       "scan-ocr.pdf",
+		"receipt.JPG":    "receipt-ocr.pdf",
+		"dir/report.pdf": "report-ocr.pdf",
+		"":               "document-ocr.pdf",
+	}
+	for in, want := range tests {
+		if got := searchablePDFName(in); got != want {
+			t.Errorf("searchablePDFName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestOCRHandler_Image(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{pages: []ocr.PageContent{{Page: 1, Content: handlerExpectedText}}}
+	handler := NewOCRHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+
+	r.POST("/ocr", handler.HandleImage)
+
+	req := newMultipartRequest(t, nil)
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	if !svc.imageCalled {
+		t.Fatal("expected image processing to be invoked")
+	}
+	if body := w.Body.String(); !strings.Contains(body, handlerExpectedText) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestOCRHandler_UnsupportedImage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandler(&fakeService{err: fmt.Errorf("persist upload: %w", ocr.ErrUnsupportedImage)})
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+
+	r.POST("/ocr", handler.HandleImage)
+
+	req := newMultipartRequest(t, nil)
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415 got %d", w.Code)
+	}
+}
+
+func TestOCRHandler_EncryptedPDF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, err := range []error{ocr.ErrPasswordRequired, ocr.ErrWrongPassword} {
+		handler := NewOCRHandler(&fakeService{err: fmt.Errorf("split pdf: %w", err)})
+
+		w := httptest.NewRecorder()
+		c, r := gin.CreateTestContext(w)
+
+		r.POST("/ocr", handler.HandleOCR)
+
+		req := newMultipartRequest(t, nil)
+		c.Request = req
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%v: expected 422 got %d", err, w.Code)
+		}
+	}
+}
+
+func TestOCRHandler_PagesOutOfRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewOCRHandler(&fakeService{err: fmt.Errorf("split pdf: %w", ocr.ErrPageOutOfRange)})
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, map[string]string{"pages": "99"})
+	c.Request = req
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 got %d", w.Code)
+	}
+}
+
 func newMultipartRequest(t *testing.T, fields map[string]string) *http.Request {
 	t.Helper()
 	body := &bytes.Buffer{}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+
+	"app/internal/ocr"
+	"app/pkg"
+)
+
+// DefaultLanguage is used when a request does not name its languages and Config.DefaultLanguage is empty.
+const DefaultLanguage = "eng+chi_sim+ind"
+
+// defaultMultipartMemory is used when Config.MultipartMemory is not set.
+const defaultMultipartMemory = 100 << 20
+
+// Config holds the request defaults and limits shared by the OCR and job handlers.
+type Config struct {
+	Languages       *ocr.LanguageSet // Installed languages; requests are not validated when nil
+	DefaultLanguage string           // Used when a request has no lang (default: DefaultLanguage)
+	MultipartMemory int64            // Upload bytes kept in memory, the rest spills to temp files (default: 100MB)
+	Correction      bool             // Whether requests may ask for LLM correction with correct=true
+}
+
+func (c Config) defaultLanguage() string {
+	if c.DefaultLanguage == "" {
+		return DefaultLanguage
+	}
+	return c.DefaultLanguage
+}
+
+func (c Config) multipartMemory() int64 {
+	if c.MultipartMemory <= 0 {
+		return defaultMultipartMemory
+	}
+	return c.MultipartMemory
+}
+
+const (
+	maxTextThreshold = 100000
+	maxOptionsSize   = 64 << 10
+)
+
+// requestOptions are the OCR settings a client may send, either as form fields
+// or as a JSON "options" part. Form fields take precedence over the JSON part.
+type requestOptions struct {
+	Language        *string `json:"lang"`
+	Output          *string `json:"output"`
+	Normalize       *string `json:"normalize"`
+	Pages           *string `json:"pages"`
+	Password        *string `json:"password"`
+	Summary         *bool   `json:"summary"`
+	TextThreshold   *int    `json:"text_threshold"`
+	ForceOCR        *bool   `json:"force_ocr"`
+	RemoveWatermark *bool   `json:"remove_watermark"`
+	Correct         *bool   `json:"correct"`
+}
+
+// parseOptions reads the JSON options part and the option form fields of a multipart form.
+func parseOptions(form *multipart.Form) (requestOptions, error) {
+	var opts requestOptions
+
+	data, err := optionsPart(form)
+	if err != nil {
+		return opts, err
+	}
+	if data != nil {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&opts); err != nil {
+			return opts, fmt.Errorf("invalid options: %v", err)
+		}
+	}
+
+	for name, dst := range map[string]**string{
+		"lang":      &opts.Language,
+		"output":    &opts.Output,
+		"normalize": &opts.Normalize,
+		"pages":     &opts.Pages,
+		"password":  &opts.Password,
+	} {
+		if value := formValue(form, name); value != "" {
+			*dst = &value
+		}
+	}
+
+	for name, dst := range map[string]**bool{
+		"summary":          &opts.Summary,
+		"force_ocr":        &opts.ForceOCR,
+		"remove_watermark": &opts.RemoveWatermark,
+		"correct":          &opts.Correct,
+	} {
+		if value := formValue(form, name); value != "" {
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s flag", name)
+			}
+			*dst = &flag
+		}
+	}
+
+	if value := formValue(form, "text_threshold"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil {
+			return opts, errors.New("invalid text_threshold")
+		}
+		opts.TextThreshold = &threshold
+	}
+
+	return opts, nil
+}
+
+// apply validates the options and stores them on req. Requested languages, or the osd
+// pack for lang=auto, are checked against cfg.Languages when it is non-nil, and
+// correct=true is rejected unless cfg.Correction is set.
+func (o requestOptions) apply(req *upload, cfg Config) error {
+	languages := cfg.Languages
+	req.opts.Language = cfg.defaultLanguage()
+	if o.Language != nil && *o.Language != "" {
+		if !ocr.LanguagePattern.MatchString(*o.Language) {
+			return errors.New("invalid lang")
+		}
+		switch {
+		case languages == nil:
+		case *o.Language == ocr.LanguageAuto:
+			if !languages.SupportsAuto() {
+				return fmt.Errorf("%w: osd pack required for auto", ocr.ErrUnsupportedLanguage)
+			}
+		default:
+			if err := languages.Validate(*o.Language); err != nil {
+				return err
+			}
+		}
+		req.opts.Language = *o.Language
+	}
+
+	switch deref(o.Output) {
+	case "", "text":
+	case "layout":
+		req.opts.Layout = true
+	case "pdf":
+		req.pdf = true
+	default:
+		return errors.New("invalid output mode")
+	}
+
+	normalization, err := pkg.ParseNormalization(deref(o.Normalize))
+	if err != nil {
+		return errors.New("invalid normalize mode")
+	}
+	req.opts.Normalization = normalization
+
+	if _, err := ocr.ParsePageSet(deref(o.Pages)); err != nil {
+		return errors.New("invalid pages")
+	}
+	req.opts.Pages = deref(o.Pages)
+	req.opts.Password = deref(o.Password)
+
+	if o.TextThreshold != nil {
+		if *o.TextThreshold < 0 || *o.TextThreshold > maxTextThreshold {
+			return fmt.Errorf("text_threshold must be between 0 and %d", maxTextThreshold)
+		}
+		req.opts.TextThreshold = *o.TextThreshold
+	}
+
+	if deref(o.Correct) && !cfg.Correction {
+		return errors.New("correct: llm correction is not enabled on this server")
+	}
+	req.opts.Correct = deref(o.Correct)
+
+	req.summary = deref(o.Summary)
+	req.opts.ForceOCR = deref(o.ForceOCR)
+	// Watermarks are removed unless OCR is forced, when the text layer is not read anyway
+	req.opts.RemoveWatermark = !req.opts.ForceOCR
+	if o.RemoveWatermark != nil {
+		req.opts.RemoveWatermark = *o.RemoveWatermark
+	}
+	return nil
+}
+
+// optionsPart returns the JSON "options" part, sent either as a form value or as a file, or nil if absent.
+func optionsPart(form *multipart.Form) ([]byte, error) {
+	return formPart(form, "options", maxOptionsSize)
+}
+
+// formPart returns the named part, sent either as a form value or as a file of at most
+// maxSize bytes, or nil if absent.
+func formPart(form *multipart.Form, name string, maxSize int64) ([]byte, error) {
+	if value := formValue(form, name); value != "" {
+		return []byte(value), nil
+	}
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	f, err := files[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("invalid %s: too large", name)
+	}
+	return data, nil
+}
+
+func formValue(form *multipart.Form, name string) string {
+	if values := form.Value[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"app/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessChecker defines the readiness check dependency.
+type ReadinessChecker interface {
+	Check(ctx context.Context) health.Report
+}
+
+// ReadyHandler reports whether the OCR toolchain and host can serve requests.
+type ReadyHandler struct {
+	checker ReadinessChecker
+}
+
+// NewReadyHandler creates a new ReadyHandler.
+func NewReadyHandler(checker ReadinessChecker) *ReadyHandler {
+	return &ReadyHandler{checker: checker}
+}
+
+// HandleReady runs the readiness checks and returns their results, with
+// 503 Service Unavailable when a required check failed.
+func (h *ReadyHandler) HandleReady(c *gin.Context) {
+	report := h.checker.Check(c.Request.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
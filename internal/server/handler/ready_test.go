@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeChecker struct {
+	report health.Report
+}
+
+func (f *fakeChecker) Check(ctx context.Context) health.Report {
+	return f.report
+}
+
+func TestReadyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		status string
+		code   int
+	}{
+		{health.ReportReady, http.StatusOK},
+		{health.ReportDegraded, http.StatusOK},
+		{health.ReportNotReady, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		checks := []health.Result{{Name: "pdftotext", Status: health.StatusFail, Required: true, Error: "not found"}}
+		handler := NewReadyHandler(&fakeChecker{report: health.Report{Status: tt.status, Checks: checks}})
+
+		r := gin.New()
+		r.GET("/readyz", handler.HandleReady)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		if w.Code != tt.code {
+			t.Fatalf("%s: expected %d got %d", tt.status, tt.code, w.Code)
+		}
+		var body health.Report
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.Status != tt.status || len(body.Checks) != 1 || body.Checks[0].Error != "not found" {
+			t.Fatalf("%s: unexpected body %s", tt.status, w.Body.String())
+		}
+	}
+}
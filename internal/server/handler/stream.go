@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"app/internal/ocr"
+	"app/internal/server/middleware"
+	"app/internal/server/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Streaming formats negotiated through the Accept header.
+const (
+	formatNDJSON = "application/x-ndjson"
+	formatSSE    = "text/event-stream"
+)
+
+// Stream event names.
+const (
+	eventProgress = "progress"
+	eventPage     = "page"
+	eventSummary  = "summary"
+	eventError    = "error"
+)
+
+// progressEvent reports how many of the selected pages have finished.
+type progressEvent struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// errorEvent ends a stream that failed after the response started.
+type errorEvent struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Status    int    `json:"status"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ndjsonEvent is one line of an NDJSON stream.
+type ndjsonEvent struct {
+	Event string `json:"event"`
+	Data  any    `json:"data"`
+}
+
+// streamFormat returns the streaming format the client prefers in its Accept header, or ""
+// for a regular JSON response. A stream is chosen when its q-value is at least that of
+// JSON; wildcards match JSON but never select a stream.
+func streamFormat(c *gin.Context) string {
+	ranges := parseAccept(c.GetHeader("Accept"))
+	if len(ranges) == 0 {
+		return ""
+	}
+
+	format, best := "", acceptQuality(ranges, "application/json")
+	for _, r := range ranges {
+		switch r.mediaType {
+		case formatNDJSON, formatSSE:
+			if r.q > 0 && (r.q > best || format == "" && r.q == best) {
+				format, best = r.mediaType, r.q
+			}
+		}
+	}
+	return format
+}
+
+// acceptRange is one media range of an Accept header with its q-value.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept reads the media ranges of an Accept header, skipping malformed ones.
+// Ranges without a valid q parameter get q=1.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, accept := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 && parsed <= 1 {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the q-value of the most specific range matching mediaType,
+// or 0 when none does.
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, 0
+	for _, r := range ranges {
+		var s int
+		switch r.mediaType {
+		case mediaType:
+			s = 3
+		case mainType + "/*":
+			s = 2
+		case "*/*":
+			s = 1
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// pageStream writes stream events, starting the response with the first one.
+type pageStream struct {
+	c       *gin.Context
+	format  string
+	started bool
+}
+
+func (s *pageStream) send(event string, data any) {
+	if !s.started {
+		s.c.Header("Content-Type", s.format)
+		s.c.Header("Cache-Control", "no-cache")
+		s.c.Header("X-Accel-Buffering", "no")
+		s.c.Status(http.StatusOK)
+		s.started = true
+	}
+
+	if s.format == formatSSE {
+		s.c.SSEvent(event, data)
+	} else if err := json.NewEncoder(s.c.Writer).Encode(ndjsonEvent{Event: event, Data: data}); err != nil {
+		log.Printf("ocr stream: write %s event: %v", event, err)
+	}
+	s.c.Writer.Flush()
+}
+
+// respondStream runs OCR and streams progress and each page as it finishes, followed by
+// a summary event. Failures before the first event get a regular JSON error response;
+// later ones end the stream with an error event.
+func (h *OCRHandler) respondStream(c *gin.Context, process processFunc, req upload, format string) {
+	stream := &pageStream{c: c, format: format}
+
+	// ExtractText serializes progress callbacks, so events never interleave
+	req.opts.OnProgress = func(p ocr.Progress) {
+		stream.send(eventProgress, progressEvent{Done: p.Done, Total: p.Total})
+		if p.Page != nil && p.Page.Content != "" {
+			stream.send(eventPage, p.Page)
+		}
+	}
+
+	// The cache status is known before the first event, so it can still go out as a header
+	ctx := service.WithCacheStatus(requestContext(c), func(status service.CacheStatus) {
+		c.Header(CacheHeader, string(status))
+	})
+	result, err := process(ctx, req.file, req.header, req.opts)
+	if err != nil {
+		if !stream.started {
+			abortWithOCRError(c, err)
+			return
+		}
+		logError(c, "ocr error", err)
+		f := classifyOCRError(err)
+		stream.send(eventError, errorEvent{Error: f.message, Code: f.code, Status: f.status, RequestID: middleware.RequestID(c)})
+		return
+	}
+
+	stream.send(eventSummary, ocr.Summarize(result.Pages))
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"app/internal/ocr"
+	"app/internal/server/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", ""},
+		{"application/json", ""},
+		{"*/*", ""},
+		{"application/x-ndjson", formatNDJSON},
+		{"text/event-stream", formatSSE},
+		{"application/json;q=0.9, text/event-stream", formatSSE},
+		{"application/x-ndjson; charset=utf-8", formatNDJSON},
+		{"application/json, application/x-ndjson;q=0.1", ""},
+		{"application/x-ndjson;q=0.5, text/event-stream;q=0.8", formatSSE},
+		{"text/event-stream, application/x-ndjson", formatSSE},
+		{"application/x-ndjson, */*;q=0.1", formatNDJSON},
+		{"application/*;q=0.2, text/event-stream;q=0.1", ""},
+		{"application/x-ndjson;q=0", ""},
+		{"application/json;q=0, text/event-stream;q=0.1", formatSSE},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/ocr", nil)
+		c.Request.Header.Set("Accept", tt.accept)
+		if got := streamFormat(c); got != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.accept, tt.expected, got)
+		}
+	}
+}
+
+func TestOCRHandler_StreamNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{pages: []ocr.PageContent{
+		{Page: 1, Content: handlerExpectedText, Source: ocr.SourceOCR, Chars: 17},
+		{Page: 2, Source: ocr.SourceOCR},
+		{Page: 3, Content: "text layer", Source: ocr.SourceTextLayer, Chars: 10},
+	}}
+	w := serveStream(t, svc, "application/x-ndjson")
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != formatNDJSON {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var events []string
+	var pages []int
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var event struct {
+			Event string          `json:"event"`
+			Data  json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event.Event)
+		if event.Event == eventPage {
+			var page ocr.PageContent
+			json.Unmarshal(event.Data, &page)
+			pages = append(pages, page.Page)
+		}
+		if event.Event == eventSummary {
+			var summary ocr.Summary
+			json.Unmarshal(event.Data, &summary)
+			if summary.Chars != 27 {
+				t.Errorf("unexpected summary: %s", event.Data)
+			}
+		}
+	}
+
+	// Pages without text only advance the progress
+	expected := "progress progress page progress progress page summary"
+	if got := strings.Join(events, " "); got != expected {
+		t.Fatalf("expected events %q, got %q", expected, got)
+	}
+	if len(pages) != 2 || pages[0] != 1 || pages[1] != 3 {
+		t.Fatalf("unexpected streamed pages: %v", pages)
+	}
+}
+
+func TestOCRHandler_StreamSSE(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{pages: []ocr.PageContent{{Page: 1, Content: handlerExpectedText}}}
+	w := serveStream(t, svc, "text/event-stream")
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), formatSSE) {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{"event:progress\ndata:{\"done\":0,\"total\":1}", "event:page\ndata:{\"page\":1", "event:summary\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in stream:\n%s", want, body)
+		}
+	}
+}
+
+func TestOCRHandler_StreamCacheHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{pages: []ocr.PageContent{{Page: 1, Content: handlerExpectedText}}, cache: service.CacheHit}
+	w := serveStream(t, svc, "application/x-ndjson")
+
+	if w.Code != http.StatusOK || w.Header().Get(CacheHeader) != string(service.CacheHit) {
+		t.Fatalf("expected %s hit on the stream, got %d %q", CacheHeader, w.Code, w.Header().Get(CacheHeader))
+	}
+}
+
+func TestOCRHandler_StreamErrorBeforeStart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := serveStream(t, &fakeService{err: errors.New("boom")}, "application/x-ndjson")
+
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502 got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"error":"ocr error"`) {
+		t.Fatalf("expected JSON error, got %s", w.Body.String())
+	}
+}
+
+func TestOCRHandler_StreamErrorMidStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeService{pages: []ocr.PageContent{{Page: 1, Content: handlerExpectedText}}, err: errors.New("boom"), midStream: true}
+	w := serveStream(t, svc, "application/x-ndjson")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	last := lines[len(lines)-1]
+	if last != `{"event":"error","data":{"error":"ocr error","code":"ocr_failed","status":502}}` {
+		t.Fatalf("unexpected final event: %s", last)
+	}
+	if strings.Contains(w.Body.String(), eventSummary) {
+		t.Fatal("expected no summary after an error")
+	}
+}
+
+func serveStream(t *testing.T, svc *fakeService, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := NewOCRHandler(svc)
+
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	r.POST("/ocr", handler.HandleOCR)
+
+	req := newMultipartRequest(t, nil)
+	req.Header.Set("Accept", accept)
+	c.Request = req
+	r.ServeHTTP(w, req)
+	return w
+}
@@ -1,25 +1,60 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"slices"
+	"strings"
+
+	"app/internal/auth"
 
 	"github.com/gin-gonic/gin"
 )
 
-// WithAPIKey enforces the x-api-key header when key is non-empty.
-// Returns a Gin middleware function.
-func WithAPIKey(key string) gin.HandlerFunc {
+// APIKeyHeader carries the client's API key.
+const APIKeyHeader = "x-api-key"
+
+const clientKey = "client"
+
+// Authenticator resolves API keys to the client keys they belong to, such as an auth.Keyring.
+type Authenticator interface {
+	Authenticate(secret string) (auth.Key, error)
+}
+
+// WithAuth requires an x-api-key header that a accepts and that grants one of scopes, and
+// records the key's name for Client. No scopes accepts any valid key, and a nil a disables
+// authentication.
+func WithAuth(a Authenticator, scopes ...auth.Scope) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// If no API key is configured, skip validation
-		if key == "" {
+		if a == nil {
 			c.Next()
 			return
 		}
 
-		// Check if the x-api-key header matches
-		if c.GetHeader("x-api-key") != key {
+		key, err := a.Authenticate(c.GetHeader(APIKeyHeader))
+		if err != nil {
+			message := "unauthorized"
+			if errors.Is(err, auth.ErrExpiredKey) {
+				message = "api key expired"
+			}
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "unauthorized",
+				"error":      message,
+				"code":       "unauthorized",
+				"request_id": RequestID(c),
+			})
+			return
+		}
+		c.Set(clientKey, key.Name)
+
+		if len(scopes) > 0 && !slices.ContainsFunc(scopes, key.Allows) {
+			names := make([]string, len(scopes))
+			for i, scope := range scopes {
+				names[i] = string(scope)
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "api key lacks scope " + strings.Join(names, " or "),
+				"code":       "forbidden",
+				"request_id": RequestID(c),
 			})
 			return
 		}
@@ -27,3 +62,22 @@ func WithAPIKey(key string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// WithAPIKey enforces the x-api-key header when key is non-empty.
+// Returns a Gin middleware function.
+func WithAPIKey(key string) gin.HandlerFunc {
+	if key == "" {
+		return WithAuth(nil)
+	}
+	keyring, err := auth.NewKeyring([]auth.Key{auth.SingleKey(key)})
+	if err != nil {
+		panic(err) // A single default key is always valid
+	}
+	return WithAuth(keyring)
+}
+
+// Client returns the name of the key the request authenticated with, or "" when
+// authentication is disabled or failed.
+func Client(c *gin.Context) string {
+	return c.GetString(clientKey)
+}
@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"crypto/sha256"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"app/internal/auth"
 
 	"github.com/gin-gonic/gin"
 )
@@ -91,3 +96,59 @@ func TestWithAPIKey_Disabled(t *testing.T) {
 		t.Fatalf("expected downstream status when disabled, got %d", w.Code)
 	}
 }
+
+func TestWithAuth_Scopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyring, err := auth.NewKeyring([]auth.Key{
+		{Name: "billing", Hash: sha256.Sum256([]byte("billing-secret")), Scopes: []auth.Scope{auth.ScopeOCRRead}},
+		{Name: "old", Hash: sha256.Sum256([]byte("old-secret")), Scopes: []auth.Scope{auth.ScopeAdmin}, Expires: time.Now().Add(-time.Hour)},
+		auth.SingleKey("root-secret"),
+	})
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+
+	var client string
+	r := gin.New()
+	r.GET("/ocr", WithAuth(keyring, auth.ScopeOCRRead), func(c *gin.Context) {
+		client = Client(c)
+		c.Status(http.StatusTeapot)
+	})
+	r.GET("/jobs", WithAuth(keyring, auth.ScopeJobsWrite), func(c *gin.Context) {
+		client = Client(c)
+		c.Status(http.StatusTeapot)
+	})
+	r.GET("/either", WithAuth(keyring, auth.ScopeJobsWrite, auth.ScopeOCRRead), func(c *gin.Context) {
+		client = Client(c)
+		c.Status(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		path   string
+		key    string
+		status int
+		client string
+		body   string
+	}{
+		{"/ocr", "billing-secret", http.StatusTeapot, "billing", ""},
+		{"/jobs", "billing-secret", http.StatusForbidden, "", `"code":"forbidden"`},
+		{"/jobs", "billing-secret", http.StatusForbidden, "", "lacks scope jobs:write"},
+		{"/jobs", "root-secret", http.StatusTeapot, "default", ""},
+		{"/either", "billing-secret", http.StatusTeapot, "billing", ""},
+		{"/ocr", "old-secret", http.StatusUnauthorized, "", "api key expired"},
+		{"/ocr", "", http.StatusUnauthorized, "", `"code":"unauthorized"`},
+	}
+
+	for _, tt := range tests {
+		client = ""
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(APIKeyHeader, tt.key)
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status || client != tt.client || !strings.Contains(w.Body.String(), tt.body) {
+			t.Fatalf("%s with %q: expected %d for %q, got %d for %q: %s", tt.path, tt.key, tt.status, tt.client, w.Code, client, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"app/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels requests that matched no route, so arbitrary paths do not
+// create new metric series.
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels requests with nonstandard methods, for the same reason.
+const otherMethod = "other"
+
+// standardMethods are the HTTP methods counted under their own name.
+var standardMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// WithMetrics counts finished requests by method, route pattern and status code.
+func WithMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		if !standardMethods[method] {
+			method = otherMethod
+		}
+		metrics.HTTPRequests.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+	}
+}
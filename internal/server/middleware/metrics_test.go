@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWithMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(WithMetrics())
+	r.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusTeapot)
+	})
+
+	matched := metrics.HTTPRequests.Value(http.MethodGet, "/items/:id", "418")
+	unmatched := metrics.HTTPRequests.Value(http.MethodGet, unmatchedRoute, "404")
+
+	for _, path := range []string{"/items/1", "/items/2", "/elsewhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := metrics.HTTPRequests.Value(http.MethodGet, "/items/:id", "418") - matched; got != 2 {
+		t.Errorf("expected 2 requests counted by route pattern, got %v", got)
+	}
+	if got := metrics.HTTPRequests.Value(http.MethodGet, unmatchedRoute, "404") - unmatched; got != 1 {
+		t.Errorf("expected 1 unmatched request, got %v", got)
+	}
+}
+
+func TestWithMetrics_NonstandardMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(WithMetrics())
+
+	other := metrics.HTTPRequests.Value(otherMethod, unmatchedRoute, "404")
+	for _, method := range []string{"FOO", "BAR1", "get"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/items", nil))
+	}
+
+	if got := metrics.HTTPRequests.Value(otherMethod, unmatchedRoute, "404") - other; got != 3 {
+		t.Errorf("expected 3 requests counted as %q, got %v", otherMethod, got)
+	}
+	if got := metrics.HTTPRequests.Value("FOO", unmatchedRoute, "404"); got != 0 {
+		t.Errorf("expected no series for a made-up method, got %v", got)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWithRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		incoming string
+		reused   bool
+	}{
+		{"generated", "", false},
+		{"reused", "abc-123.x_y", true},
+		{"invalid", "bad id\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			r := gin.New()
+			r.Use(WithRequestID())
+			r.GET("/test", func(c *gin.Context) {
+				seen = RequestID(c)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			header := w.Header().Get(RequestIDHeader)
+			if seen == "" || header != seen {
+				t.Fatalf("expected the request ID %q in the response header, got %q", seen, header)
+			}
+			if (seen == tt.incoming) != tt.reused {
+				t.Fatalf("unexpected request ID %q for incoming %q", seen, tt.incoming)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package router
 
 import (
 	"net/http"
-	"net/http/httputil"
-	"net/url"
+
+	"app/internal/auth"
+	"app/internal/metrics"
+	"app/internal/server/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,45 +13,109 @@ import (
 // OCRHandler defines the interface for the OCR handler.
 type OCRHandler interface {
 	HandleOCR(c *gin.Context)
+	HandleImage(c *gin.Context)
+	HandleLanguages(c *gin.Context)
+}
+
+// JobHandler defines the interface for the asynchronous OCR job handler.
+type JobHandler interface {
+	HandleSubmit(c *gin.Context)
+	HandleStatus(c *gin.Context)
+	HandleResult(c *gin.Context)
+	HandlePDF(c *gin.Context)
+	HandleCancel(c *gin.Context)
+}
+
+// ReadyHandler defines the interface for the readiness handler.
+type ReadyHandler interface {
+	HandleReady(c *gin.Context)
+}
+
+// ExtractHandler defines the interface for the field extraction handler.
+type ExtractHandler interface {
+	HandleExtract(c *gin.Context)
+}
+
+// Config holds the router settings.
+type Config struct {
+	Auth   middleware.Authenticator // Checks the x-api-key header of OCR, job and upstream routes
+	APIKey string                   // Single key with every scope, used when Auth is nil
+	Ollama *Upstream                // Ollama server proxied under its prefix; nil disables the proxy
+}
+
+// authenticator returns the configured Authenticator, or nil when authentication is disabled.
+func (cfg Config) authenticator() middleware.Authenticator {
+	if cfg.Auth != nil {
+		return cfg.Auth
+	}
+	if cfg.APIKey == "" {
+		return nil
+	}
+	keyring, err := auth.NewKeyring([]auth.Key{auth.SingleKey(cfg.APIKey)})
+	if err != nil {
+		panic(err) // A single default key is always valid
+	}
+	return keyring
 }
 
 // New wires up handlers to the Gin engine.
-func New(apiKey string, ocrHandler OCRHandler) *gin.Engine {
+// Job, readiness and extraction routes are only registered when their handler is non-nil.
+func New(cfg Config, ocrHandler OCRHandler, jobHandler JobHandler, readyHandler ReadyHandler, extractHandler ExtractHandler) *gin.Engine {
 	r := gin.Default()
 
-	
+	// Tag every request with an ID for log correlation
+	r.Use(middleware.WithRequestID())
+	r.Use(middleware.WithMetrics())
+
 	// Health check endpoint (no middleware)
 	r.GET("/healthz", func(c *gin.Context) {
 		c.String(http.StatusOK, "ok")
 	})
-	// Proxy to port 11434 (Ollama)
-	ollamaURL, _ := url.Parse("http://localhost:11434")
-	proxy := httputil.NewSingleHostReverseProxy(ollamaURL)
-
+	// Readiness check of the OCR toolchain (no middleware)
+	if readyHandler != nil {
+		r.GET("/readyz", readyHandler.HandleReady)
+	}
+	// Prometheus metrics (no middleware)
+	r.GET("/metrics", gin.WrapH(metrics.Default))
+	// Unmatched routes, including the Ollama prefix when the proxy is disabled
 	r.NoRoute(func(c *gin.Context) {
-		proxy.ServeHTTP(c.Writer, c.Request)
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error":      "not found",
+			"code":       "not_found",
+			"request_id": middleware.RequestID(c),
+		})
 	})
 
+	// Each route group requires a key with its scope; admin keys have every scope
+	authenticator := cfg.authenticator()
+	if cfg.Ollama != nil {
+		cfg.Ollama.mount(r, middleware.WithAuth(authenticator, auth.ScopeAdmin))
+	}
+
 	// API v1 group
 	v1 := r.Group("/api/v1")
 	{
 		// OCR endpoints group with API key middleware
 		ocr := v1.Group("/ocr")
+		read := ocr.Group("", middleware.WithAuth(authenticator, auth.ScopeOCRRead))
 
-		// Apply API key middleware if configured
-		if apiKey != "" {
-			ocr.Use(func(c *gin.Context) {
-				if c.GetHeader("x-api-key") != apiKey {
-					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-						"error": "unauthorized",
-					})
-					return
-				}
-				c.Next()
-			})
+		read.POST("/pdf", ocrHandler.HandleOCR)
+		read.POST("/image", ocrHandler.HandleImage)
+		read.GET("/languages", ocrHandler.HandleLanguages)
+		if extractHandler != nil {
+			read.POST("/extract", extractHandler.HandleExtract)
 		}
 
-		ocr.POST("/pdf", ocrHandler.HandleOCR)
+		if jobHandler != nil {
+			// Reading a job's status and results is also open to ocr:read keys
+			jobs := ocr.Group("/jobs", middleware.WithAuth(authenticator, auth.ScopeJobsWrite))
+			jobReads := ocr.Group("/jobs", middleware.WithAuth(authenticator, auth.ScopeJobsWrite, auth.ScopeOCRRead))
+			jobs.POST("", jobHandler.HandleSubmit)
+			jobReads.GET("/:id", jobHandler.HandleStatus)
+			jobReads.GET("/:id/result", jobHandler.HandleResult)
+			jobReads.GET("/:id/pdf", jobHandler.HandlePDF)
+			jobs.DELETE("/:id", jobHandler.HandleCancel)
+		}
 	}
 
 	return r
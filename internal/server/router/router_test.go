@@ -1,15 +1,21 @@
 package router
 
 import (
+	"crypto/sha256"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"app/internal/auth"
+
 	"github.com/gin-gonic/gin"
 )
 
 type fakeOCRHandler struct {
-	called bool
+	called          bool
+	imageCalled     bool
+	languagesCalled bool
 }
 
 func (f *fakeOCRHandler) HandleOCR(c *gin.Context) {
@@ -17,10 +23,20 @@ func (f *fakeOCRHandler) HandleOCR(c *gin.Context) {
 	c.Status(http.StatusAccepted)
 }
 
+func (f *fakeOCRHandler) HandleImage(c *gin.Context) {
+	f.imageCalled = true
+	c.Status(http.StatusAccepted)
+}
+
+func (f *fakeOCRHandler) HandleLanguages(c *gin.Context) {
+	f.languagesCalled = true
+	c.Status(http.StatusOK)
+}
+
 func TestNew_Healthz(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	router := New("", &fakeOCRHandler{})
+	router := New(Config{}, &fakeOCRHandler{}, nil, nil, nil)
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
@@ -34,11 +50,47 @@ func TestNew_Healthz(t *testing.T) {
 	}
 }
 
+type fakeReadyHandler struct{}
+
+func (fakeReadyHandler) HandleReady(c *gin.Context) {
+	c.Status(http.StatusServiceUnavailable)
+}
+
+func TestNew_Readyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Served without the API key
+	router := New(Config{APIKey: "secret-key"}, &fakeOCRHandler{}, nil, fakeReadyHandler{}, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected ready handler status 503, got %d", w.Code)
+	}
+}
+
+func TestNew_Metrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New(Config{APIKey: "secret-key"}, &fakeOCRHandler{}, nil, nil, nil)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/ocr/pdf", nil))
+
+	// Served without the API key
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `ocr_http_requests_total{method="POST",route="/api/v1/ocr/pdf",status="401"}`) {
+		t.Fatalf("expected request counter in metrics, got:\n%s", body)
+	}
+}
+
 func TestNew_OCRHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	fakeHandler := &fakeOCRHandler{}
-	router := New("", fakeHandler)
+	router := New(Config{}, fakeHandler, nil, nil, nil)
 
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/ocr/pdf", nil)
@@ -52,11 +104,108 @@ func TestNew_OCRHandler(t *testing.T) {
 	}
 }
 
+func TestNew_ImageHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fakeHandler := &fakeOCRHandler{}
+	router := New(Config{APIKey: "secret-key"}, fakeHandler, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ocr/image", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized || fakeHandler.imageCalled {
+		t.Fatalf("expected 401 without API key, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/ocr/image", nil)
+	req.Header.Set("x-api-key", "secret-key")
+	router.ServeHTTP(w, req)
+
+	if !fakeHandler.imageCalled {
+		t.Fatal("expected image handler to be invoked")
+	}
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+}
+
+func TestNew_LanguagesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fakeHandler := &fakeOCRHandler{}
+	router := New(Config{}, fakeHandler, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ocr/languages", nil)
+	router.ServeHTTP(w, req)
+
+	if !fakeHandler.languagesCalled || w.Code != http.StatusOK {
+		t.Fatalf("expected languages handler to be invoked, got %d", w.Code)
+	}
+}
+
+type fakeJobHandler struct {
+	called []string
+}
+
+func (f *fakeJobHandler) HandleSubmit(c *gin.Context) { f.record(c, "submit") }
+func (f *fakeJobHandler) HandleStatus(c *gin.Context) { f.record(c, "status") }
+func (f *fakeJobHandler) HandleResult(c *gin.Context) { f.record(c, "result") }
+func (f *fakeJobHandler) HandlePDF(c *gin.Context)    { f.record(c, "pdf") }
+func (f *fakeJobHandler) HandleCancel(c *gin.Context) { f.record(c, "cancel") }
+
+func (f *fakeJobHandler) record(c *gin.Context, name string) {
+	f.called = append(f.called, name+":"+c.Param("id"))
+	c.Status(http.StatusAccepted)
+}
+
+func TestNew_JobRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jobs := &fakeJobHandler{}
+	router := New(Config{APIKey: "secret-key"}, &fakeOCRHandler{}, jobs, nil, nil)
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/v1/ocr/jobs"},
+		{http.MethodGet, "/api/v1/ocr/jobs/abc"},
+		{http.MethodGet, "/api/v1/ocr/jobs/abc/result"},
+		{http.MethodGet, "/api/v1/ocr/jobs/abc/pdf"},
+		{http.MethodDelete, "/api/v1/ocr/jobs/abc"},
+	}
+
+	for _, r := range requests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(r.method, r.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("%s %s: expected 401 without API key, got %d", r.method, r.path, w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(r.method, r.path, nil)
+		req.Header.Set("x-api-key", "secret-key")
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("%s %s: unexpected status %d", r.method, r.path, w.Code)
+		}
+	}
+
+	expected := []string{"submit:", "status:abc", "result:abc", "pdf:abc", "cancel:abc"}
+	if strings.Join(jobs.called, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected handler calls: %v", jobs.called)
+	}
+}
+
 func TestNew_WithAPIKey(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	fakeHandler := &fakeOCRHandler{}
-	router := New("secret-key", fakeHandler)
+	router := New(Config{APIKey: "secret-key"}, fakeHandler, nil, nil, nil)
 
 	// Test without API key - should fail
 	w := httptest.NewRecorder()
@@ -66,6 +215,9 @@ func TestNew_WithAPIKey(t *testing.T) {
 	if w.Code != http.StatusUnauthorized {
 		t.Fatalf("expected 401 without API key, got %d", w.Code)
 	}
+	if id := w.Header().Get("X-Request-ID"); id == "" || !strings.Contains(w.Body.String(), `"request_id":"`+id+`"`) {
+		t.Fatalf("expected the request ID in the header and body, got %q: %s", id, w.Body.String())
+	}
 	if fakeHandler.called {
 		t.Fatal("handler should not be called without valid API key")
 	}
@@ -84,3 +236,85 @@ func TestNew_WithAPIKey(t *testing.T) {
 		t.Fatalf("expected 202 with valid API key, got %d", w.Code)
 	}
 }
+
+type fakeExtractHandler struct {
+	called bool
+}
+
+func (f *fakeExtractHandler) HandleExtract(c *gin.Context) {
+	f.called = true
+	c.Status(http.StatusOK)
+}
+
+func TestNew_ExtractRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	New(Config{}, &fakeOCRHandler{}, nil, nil, nil).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/ocr/extract", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without an extract handler, got %d", w.Code)
+	}
+
+	extract := &fakeExtractHandler{}
+	router := New(Config{APIKey: "secret-key"}, &fakeOCRHandler{}, nil, nil, extract)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/ocr/extract", nil))
+	if w.Code != http.StatusUnauthorized || extract.called {
+		t.Fatalf("expected 401 without API key, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ocr/extract", nil)
+	req.Header.Set("x-api-key", "secret-key")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !extract.called {
+		t.Fatalf("expected extract handler to be called, got %d", w.Code)
+	}
+}
+
+func TestNew_Scopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keyring, err := auth.NewKeyring([]auth.Key{
+		{Name: "reader", Hash: sha256.Sum256([]byte("reader-key")), Scopes: []auth.Scope{auth.ScopeOCRRead}},
+		{Name: "jobs", Hash: sha256.Sum256([]byte("jobs-key")), Scopes: []auth.Scope{auth.ScopeJobsWrite}},
+		{Name: "ops", Hash: sha256.Sum256([]byte("admin-key")), Scopes: []auth.Scope{auth.ScopeAdmin}},
+	})
+	if err != nil {
+		t.Fatalf("new keyring: %v", err)
+	}
+	// Auth takes precedence over APIKey
+	router := New(Config{Auth: keyring, APIKey: "secret-key"}, &fakeOCRHandler{}, &fakeJobHandler{}, nil, &fakeExtractHandler{})
+
+	tests := []struct {
+		method string
+		path   string
+		key    string
+		status int
+	}{
+		{http.MethodPost, "/api/v1/ocr/pdf", "reader-key", http.StatusAccepted},
+		{http.MethodPost, "/api/v1/ocr/extract", "reader-key", http.StatusOK},
+		{http.MethodPost, "/api/v1/ocr/jobs", "reader-key", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/ocr/jobs/abc", "reader-key", http.StatusAccepted},
+		{http.MethodGet, "/api/v1/ocr/jobs/abc/result", "reader-key", http.StatusAccepted},
+		{http.MethodGet, "/api/v1/ocr/jobs/abc/pdf", "reader-key", http.StatusAccepted},
+		{http.MethodDelete, "/api/v1/ocr/jobs/abc", "reader-key", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/ocr/pdf", "jobs-key", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/ocr/jobs", "jobs-key", http.StatusAccepted},
+		{http.MethodGet, "/api/v1/ocr/jobs/abc", "jobs-key", http.StatusAccepted},
+		{http.MethodPost, "/api/v1/ocr/pdf", "admin-key", http.StatusAccepted},
+		{http.MethodDelete, "/api/v1/ocr/jobs/abc", "admin-key", http.StatusAccepted},
+		{http.MethodPost, "/api/v1/ocr/pdf", "secret-key", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("x-api-key", tt.key)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Fatalf("%s %s with %s: expected %d, got %d", tt.method, tt.path, tt.key, tt.status, w.Code)
+		}
+	}
+}
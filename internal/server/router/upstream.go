@@ -0,0 +1,91 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"app/internal/server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Upstream is a backend reverse-proxied under Prefix, such as an Ollama server.
+type Upstream struct {
+	Prefix          string        // Mount path such as "/ollama"; stripped before forwarding
+	URL             *url.URL      // Target the requests are forwarded to
+	DialTimeout     time.Duration // Connecting to the target (default: 10s)
+	ResponseTimeout time.Duration // Waiting for response headers; streamed bodies are not limited (default: 5m)
+}
+
+// Error codes reported when the upstream cannot be reached.
+const (
+	codeUpstreamUnavailable = "upstream_unavailable"
+	codeUpstreamTimeout     = "upstream_timeout"
+)
+
+// mount registers the upstream proxy for every method under u.Prefix behind auth.
+func (u Upstream) mount(r *gin.Engine, auth gin.HandlerFunc) {
+	proxy := u.proxy()
+	handle := func(c *gin.Context) {
+		proxy.ServeHTTP(c.Writer, c.Request)
+	}
+
+	group := r.Group(u.Prefix, auth)
+	group.Any("", handle)
+	group.Any("/*path", handle)
+}
+
+func (u Upstream) proxy() *httputil.ReverseProxy {
+	dialTimeout := u.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 10 * time.Second
+	}
+	responseTimeout := u.ResponseTimeout
+	if responseTimeout <= 0 {
+		responseTimeout = 5 * time.Minute
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.ResponseHeaderTimeout = responseTimeout
+
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(pr.In.URL.Path, u.Prefix), "/")
+			pr.Out.URL.RawPath = ""
+			pr.SetURL(u.URL)
+			pr.SetXForwarded()
+			// The API key authenticates the caller to this service, not to the upstream
+			pr.Out.Header.Del("x-api-key")
+		},
+		Transport:    transport,
+		ErrorHandler: upstreamError,
+	}
+}
+
+// upstreamError reports a failed proxy request as a JSON error.
+func upstreamError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code, message := http.StatusBadGateway, codeUpstreamUnavailable, "upstream unavailable"
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		status, code, message = http.StatusGatewayTimeout, codeUpstreamTimeout, "upstream timed out"
+	}
+	requestID := w.Header().Get(middleware.RequestIDHeader)
+	log.Printf("proxy %s %s [request %s]: %v", r.Method, r.URL.Path, requestID, err)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	body, _ := json.Marshal(gin.H{
+		"error":      message,
+		"code":       code,
+		"request_id": requestID,
+	})
+	w.Write(body)
+}
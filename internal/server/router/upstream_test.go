@@ -0,0 +1,126 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newUpstream(t *testing.T, h http.HandlerFunc) *Upstream {
+	t.Helper()
+	backend := httptest.NewServer(h)
+	t.Cleanup(backend.Close)
+	target, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Upstream{Prefix: "/ollama", URL: target}
+}
+
+// newProxyRequest creates a request with a cancellable context, which the reverse proxy
+// watches instead of asking the recorder for close notifications.
+func newProxyRequest(t *testing.T, method, target string) *http.Request {
+	return httptest.NewRequestWithContext(t.Context(), method, target, nil)
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected JSON error body, got %q", w.Body.String())
+	}
+	return body["code"]
+}
+
+func TestNew_UpstreamDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := New(Config{}, &fakeOCRHandler{}, nil, nil, nil)
+
+	for _, path := range []string{"/ollama/api/tags", "/api/tags"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound || errorCode(t, w) != "not_found" {
+			t.Fatalf("%s: expected JSON 404, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestNew_Upstream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var gotPath, gotKey string
+	upstream := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotKey = r.URL.RequestURI(), r.Header.Get("x-api-key")
+		w.WriteHeader(http.StatusTeapot)
+	})
+	router := New(Config{APIKey: "secret-key", Ollama: upstream}, &fakeOCRHandler{}, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newProxyRequest(t, http.MethodPost, "/ollama/api/generate"))
+	if w.Code != http.StatusUnauthorized || gotPath != "" {
+		t.Fatalf("expected 401 without API key, got %d", w.Code)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/ollama/api/generate?stream=false", "/api/generate?stream=false"},
+		{"/ollama", "/"},
+	}
+	for _, tt := range tests {
+		w = httptest.NewRecorder()
+		req := newProxyRequest(t, http.MethodPost, tt.path)
+		req.Header.Set("x-api-key", "secret-key")
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusTeapot {
+			t.Fatalf("%s: expected upstream status, got %d", tt.path, w.Code)
+		}
+		if gotPath != tt.expected {
+			t.Fatalf("%s: expected upstream path %q, got %q", tt.path, tt.expected, gotPath)
+		}
+		if gotKey != "" {
+			t.Fatalf("%s: API key leaked to upstream", tt.path)
+		}
+	}
+}
+
+func TestNew_UpstreamErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	down := newUpstream(t, nil)
+	down.URL.Host = "127.0.0.1:1"
+
+	slow := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	slow.ResponseTimeout = 50 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		upstream *Upstream
+		status   int
+		code     string
+	}{
+		{"unavailable", down, http.StatusBadGateway, codeUpstreamUnavailable},
+		{"timeout", slow, http.StatusGatewayTimeout, codeUpstreamTimeout},
+	}
+	for _, tt := range tests {
+		router := New(Config{Ollama: tt.upstream}, &fakeOCRHandler{}, nil, nil, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newProxyRequest(t, http.MethodGet, "/ollama/api/tags"))
+		if w.Code != tt.status || errorCode(t, w) != tt.code {
+			t.Fatalf("%s: expected %d %s, got %d %s", tt.name, tt.status, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
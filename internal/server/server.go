@@ -1,49 +1,371 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
 	"time"
 
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/extract"
+	"app/internal/health"
+	"app/internal/job"
+	"app/internal/llm"
 	"app/internal/ocr"
 	"app/internal/server/handler"
 	"app/internal/server/router"
 	"app/internal/server/service"
+	"app/internal/webhook"
 )
 
-// Run starts the HTTP server.
-func Run() error {
-	if err := ocr.EnsureBinary(""); err != nil {
+// shutdownGrace is how long cancelled requests get to clean up after the drain deadline.
+const shutdownGrace = 10 * time.Second
+
+// Run starts the HTTP server with cfg and shuts it down gracefully on SIGINT or SIGTERM.
+func Run(cfg *config.Config) error {
+	if err := ocr.EnsureEngine(cfg.OCR.Engine); err != nil {
 		return err
 	}
 
-	// Get configuration from environment
-	apiKey := os.Getenv("API_KEY")
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Temp files go to a private root, removed on exit with whatever interrupted work left behind
+	if _, err := ocr.CreateTempRoot(); err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
 	}
+	defer func() {
+		if err := ocr.RemoveTempRoot(); err != nil {
+			log.Printf("remove temp dir: %v", err)
+		}
+	}()
 
 	// Build dependency chain
-	processor := ocr.NewProcessor()
+	processor, err := newProcessor(cfg.OCR)
+	if err != nil {
+		return err
+	}
+	languages := installedLanguages(cfg.OCR.DefaultLanguage)
+	processor.AutoLanguages = autoLanguages(languages)
+	cache, err := newOCRCache(cfg.Cache)
+	if err != nil {
+		return err
+	}
 	ocrService := service.NewOCRService(processor)
-	ocrHandler := handler.NewOCRHandler(ocrService)
+	if cache != nil {
+		ocrService = service.NewOCRServiceWithCache(processor, cache)
+	}
+	if cfg.Correction.Enabled {
+		ollama := llm.NewOllama(cfg.Ollama.URL, cfg.Ollama.Model, cfg.Correction.Timeout.Std())
+		ocrService.EnableCorrection(llm.NewCorrector(ollama), cfg.Correction.MinConfidence)
+	}
+	handlerConfig := handler.Config{
+		Languages:       languages,
+		DefaultLanguage: cfg.OCR.DefaultLanguage,
+		MultipartMemory: cfg.Server.MultipartMemoryMB << 20,
+		Correction:      cfg.Correction.Enabled,
+	}
+	ocrHandler := handler.NewOCRHandlerWithConfig(ocrService, handlerConfig)
+
+	// Asynchronous jobs persist to disk when a job store dir is set, otherwise in memory
+	jobManager, err := newJobManager(cfg.Jobs, ocrService.ProcessFile)
+	if err != nil {
+		return err
+	}
+	if err := jobManager.Start(context.Background()); err != nil {
+		return err
+	}
+	defer jobManager.Close()
+	jobHandler := handler.NewJobHandlerWithConfig(jobManager, handlerConfig)
+
+	// Field extraction uses the Ollama model when one is configured and rules otherwise
+	var extractModel llm.Generator
+	if cfg.Ollama.URL != "" && cfg.Ollama.Model != "" {
+		ollama := llm.NewOllama(cfg.Ollama.URL, cfg.Ollama.Model, cfg.Extraction.Timeout.Std())
+		ollama.Format = "json"
+		extractModel = ollama
+	}
+	extractHandler := handler.NewExtractHandler(ocrService, extract.NewExtractor(extractModel), handlerConfig)
+
+	readyHandler := handler.NewReadyHandler(newReadinessChecker(cfg))
 
 	// Setup router with all routes and middleware
-	r := router.New(apiKey, ocrHandler)
+	keyring, err := newKeyring(cfg.Server)
+	if err != nil {
+		return err
+	}
+	routerConfig := router.Config{}
+	if keyring != nil {
+		routerConfig.Auth = keyring
+		stopReload := reloadKeysOnHangup(keyring, cfg.Server)
+		defer stopReload()
+	}
+	if cfg.Ollama.URL != "" {
+		target, err := url.Parse(cfg.Ollama.URL)
+		if err != nil {
+			return fmt.Errorf("invalid ollama url: %w", err)
+		}
+		routerConfig.Ollama = &router.Upstream{
+			Prefix:          cfg.Ollama.Prefix,
+			URL:             target,
+			DialTimeout:     cfg.Ollama.DialTimeout.Std(),
+			ResponseTimeout: cfg.Ollama.Timeout.Std(),
+		}
+	}
+	r := router.New(routerConfig, ocrHandler, jobHandler, readyHandler, extractHandler)
+
+	// Request contexts derive from requestsCtx, so cancelling it stops OCR still running after the drain deadline
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 
 	// Configure server with generous timeouts for large PDF processing
-	addr := ":" + port
+	addr := ":" + strconv.Itoa(cfg.Server.Port)
 	srv := &http.Server{
 		Addr:         addr,
 		Handler:      r,
-		ReadTimeout:  120 * time.Minute, // Allow large file uploads
-		WriteTimeout: 120 * time.Minute, // Allow long OCR processing
-		IdleTimeout:  120 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout.Std(),
+		WriteTimeout: cfg.Server.WriteTimeout.Std(),
+		IdleTimeout:  cfg.Server.IdleTimeout.Std(),
+		BaseContext:  func(net.Listener) context.Context { return requestsCtx },
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
 	}
 
 	// Start server
 	log.Printf("listening on %s", addr)
-	return srv.ListenAndServe()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = serve(ctx, srv, ln, jobManager, cfg.Server.DrainTimeout.Std(), cancelRequests)
+	jobManager.Close()
+	return err
+}
+
+// newProcessor builds the OCR processor for the configured engine and limits.
+func newProcessor(cfg config.OCRConfig) (*ocr.Processor, error) {
+	processor := ocr.NewProcessor()
+	processor.Timeout = cfg.Timeout.Std()
+	processor.TextThreshold = cfg.TextThreshold
+	processor.MaxPages = cfg.MaxPages
+	if cfg.Concurrency > 0 {
+		processor.Concurrency = cfg.Concurrency
+	}
+	if cfg.PageLimit > 0 {
+		processor.PageLimit = cfg.PageLimit
+	}
+
+	rec, err := ocr.NewRecognizer(cfg.Engine, processor.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	processor.Recognizer = rec
+	return processor, nil
+}
+
+// serve runs srv on ln until ctx is done, then shuts down gracefully: new connections
+// are refused, queued jobs stay queued, and in-flight requests and running jobs get
+// drainTimeout to finish. After that, cancelRequests cancels the remaining request
+// contexts, killing their OCR subprocesses, and running jobs are interrupted.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, jobs *job.Manager, drainTimeout time.Duration, cancelRequests context.CancelFunc) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down, draining in-flight work for up to %s", drainTimeout)
+	drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	jobsDrained := make(chan error, 1)
+	go func() {
+		jobsDrained <- jobs.Shutdown(drainCtx)
+	}()
+
+	if err := srv.Shutdown(drainCtx); err != nil {
+		log.Printf("drain deadline reached, cancelling in-flight requests")
+		cancelRequests()
+
+		// Cancelled handlers return once their subprocesses exit; give them time to clean up
+		closeCtx, cancelClose := context.WithTimeout(context.Background(), shutdownGrace)
+		defer cancelClose()
+		if err := srv.Shutdown(closeCtx); err != nil {
+			srv.Close()
+		}
+	}
+	if err := <-jobsDrained; err != nil {
+		log.Printf("drain deadline reached, interrupted running jobs")
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Printf("shutdown complete")
+	return nil
+}
+
+// newKeyring loads the API keys from cfg.APIKeysFile and adds cfg.APIKey as the key named
+// "default". It returns nil, disabling authentication, when neither is set.
+func newKeyring(cfg config.ServerConfig) (*auth.Keyring, error) {
+	keys, err := loadKeys(cfg)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+	return auth.NewKeyring(keys)
+}
+
+func loadKeys(cfg config.ServerConfig) ([]auth.Key, error) {
+	var keys []auth.Key
+	if cfg.APIKeysFile != "" {
+		loaded, err := auth.LoadFile(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = loaded
+	}
+	if cfg.APIKey != "" {
+		keys = append(keys, auth.SingleKey(cfg.APIKey))
+	}
+	return keys, nil
+}
+
+// reloadKeysOnHangup reloads the API keys into keyring on SIGHUP, so keys can be added and
+// revoked without a restart. A file that fails to load leaves the current keys in place.
+// The returned function stops reloading.
+func reloadKeysOnHangup(keyring *auth.Keyring, cfg config.ServerConfig) func() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-hangup:
+				keys, err := loadKeys(cfg)
+				if err == nil {
+					err = keyring.Replace(keys)
+				}
+				if err != nil {
+					log.Printf("reload api keys: %v; keeping %d current keys", err, keyring.Len())
+					continue
+				}
+				log.Printf("reloaded %d api keys", len(keys))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(hangup)
+		close(done)
+	}
+}
+
+// installedLanguages queries tesseract for its language packs once at startup.
+// It returns nil, disabling language validation, when the query fails.
+func installedLanguages(defaultLanguage string) *ocr.LanguageSet {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	languages, err := ocr.ListLanguages(ctx, "")
+	if err != nil {
+		log.Printf("list ocr languages: %v; requested languages will not be validated", err)
+		return nil
+	}
+	if err := languages.Validate(defaultLanguage); err != nil {
+		log.Printf("default language: %v", err)
+	}
+	return languages
+}
+
+// newReadinessChecker checks the binaries the configured engine needs, the default
+// language packs, and the temp dir, where uploads and pages are written. tesseract and
+// pdftoppm are required with either engine since lang=auto and layout output use them.
+func newReadinessChecker(cfg *config.Config) *health.Checker {
+	return health.NewChecker(
+		health.Binary("ocrmypdf", cfg.OCR.Engine != ocr.EngineTesseract),
+		health.Binary("tesseract", true),
+		health.Binary("pdftoppm", true),
+		health.Binary("pdftotext", true),
+		health.Languages("", cfg.OCR.DefaultLanguage),
+		health.TempDir(ocr.TempDir()),
+		health.DiskSpace("", uint64(cfg.Server.MinFreeDiskMB)<<20),
+	)
+}
+
+// autoLanguages returns the default lang=auto candidates that are installed, or every
+// installed language when none of them are. It returns nil to use the defaults only when
+// the installed languages are unknown.
+func autoLanguages(languages *ocr.LanguageSet) []string {
+	if languages == nil {
+		return nil
+	}
+	var codes []string
+	for _, code := range ocr.DefaultAutoLanguages {
+		if languages.Has(code) {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return languages.Codes()
+	}
+	return codes
+}
+
+// newOCRCache builds the result cache: on disk under cfg.Dir, otherwise in memory.
+// It returns nil when the cache is disabled.
+func newOCRCache(cfg config.CacheConfig) (service.Cache, error) {
+	if cfg.Disabled {
+		return nil, nil
+	}
+	if cfg.Dir != "" {
+		return service.NewFileCache(cfg.Dir, int64(cfg.MaxMB)<<20, cfg.TTL.Std())
+	}
+	return service.NewMemoryCache(cfg.MaxEntries, cfg.TTL.Std()), nil
+}
+
+// newJobManager builds the job queue backed by a file store in cfg.StoreDir, or memory when
+// it is empty. Callbacks may reach public addresses and cfg.CallbackAllowedNetworks.
+func newJobManager(cfg config.JobsConfig, run job.Runner) (*job.Manager, error) {
+	networks, err := cfg.CallbackNetworks()
+	if err != nil {
+		return nil, err
+	}
+	notifier := webhook.NewNotifier()
+	notifier.AllowedNetworks = networks
+
+	if cfg.StoreDir == "" {
+		dataDir, err := os.MkdirTemp(ocr.TempDir(), "ocr-jobs-*")
+		if err != nil {
+			return nil, err
+		}
+		return job.NewManager(job.NewMemoryStore(), run, job.Config{
+			Workers:  cfg.Workers,
+			DataDir:  dataDir,
+			Notifier: notifier,
+		})
+	}
+
+	store, err := job.NewFileStore(filepath.Join(cfg.StoreDir, "jobs"))
+	if err != nil {
+		return nil, err
+	}
+	return job.NewManager(store, run, job.Config{
+		Workers:  cfg.Workers,
+		DataDir:  filepath.Join(cfg.StoreDir, "inputs"),
+		Notifier: notifier,
+	})
 }
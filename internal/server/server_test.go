@@ -3,15 +3,24 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/job"
 	"app/internal/ocr"
 	"app/internal/server/handler"
 	"app/internal/server/router"
@@ -41,7 +50,7 @@ func TestServer_OCRFlowWithAPIKey(t *testing.T) {
 	proc := &testProcessor{pages: []ocr.PageContent{{Page: 1, Content: sampleExpectedText}}}
 	svc := service.NewOCRService(proc)
 	ocrHandler := handler.NewOCRHandler(svc)
-	r := router.New("secret", ocrHandler)
+	r := router.New(router.Config{APIKey: "secret"}, ocrHandler, nil, nil, nil)
 
 	ts := httptest.NewServer(r)
 	defer ts.Close()
@@ -85,7 +94,7 @@ func TestServer_OCRFlowWithoutAPIKey(t *testing.T) {
 	proc := &testProcessor{pages: []ocr.PageContent{{Page: 1, Content: sampleExpectedText}}}
 	svc := service.NewOCRService(proc)
 	ocrHandler := handler.NewOCRHandler(svc)
-	r := router.New("", ocrHandler) // No API key
+	r := router.New(router.Config{}, ocrHandler, nil, nil, nil) // No API key
 
 	ts := httptest.NewServer(r)
 	defer ts.Close()
@@ -110,6 +119,69 @@ func TestServer_OCRFlowWithoutAPIKey(t *testing.T) {
 	}
 }
 
+func TestServer_AsyncJobFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	proc := &testProcessor{pages: []ocr.PageContent{{Page: 1, Content: sampleExpectedText}}}
+	svc := service.NewOCRService(proc)
+	manager, err := newJobManager(config.JobsConfig{Workers: 1}, svc.ProcessFile)
+	if err != nil {
+		t.Fatalf("new job manager: %v", err)
+	}
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("start job manager: %v", err)
+	}
+	defer manager.Close()
+
+	r := router.New(router.Config{}, handler.NewOCRHandler(svc), handler.NewJobHandler(manager), nil, nil)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	resp, err := http.DefaultClient.Do(newFileRequest(t, ts.URL+"/api/v1/ocr/jobs", nil))
+	if err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+	var submitted struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	json.NewDecoder(resp.Body).Decode(&submitted)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || submitted.ID == "" {
+		t.Fatalf("expected 202 with job id, got %d %+v", resp.StatusCode, submitted)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(ts.URL + "/api/v1/ocr/jobs/" + submitted.ID)
+		if err != nil {
+			t.Fatalf("status failed: %v", err)
+		}
+		var status struct {
+			Status string `json:"status"`
+		}
+		json.NewDecoder(resp.Body).Decode(&status)
+		resp.Body.Close()
+		if status.Status == "succeeded" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish, last status %q", status.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/v1/ocr/jobs/" + submitted.ID + "/result")
+	if err != nil {
+		t.Fatalf("result failed: %v", err)
+	}
+	defer resp.Body.Close()
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(bodyBytes), sampleExpectedText) {
+		t.Fatalf("unexpected result: %d %s", resp.StatusCode, bodyBytes)
+	}
+}
+
 func newFileRequest(t *testing.T, url string, fields map[string]string) *http.Request {
 	t.Helper()
 	body := &bytes.Buffer{}
@@ -130,3 +202,183 @@ func newFileRequest(t *testing.T, url string, fields map[string]string) *http.Re
 	return req
 }
 
+func TestNewOCRCache(t *testing.T) {
+	cfg := config.Default().Cache
+	cache, err := newOCRCache(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.(*service.MemoryCache); !ok {
+		t.Fatalf("expected memory cache by default, got %T", cache)
+	}
+
+	cfg.Dir = t.TempDir()
+	cache, err = newOCRCache(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.(*service.FileCache); !ok {
+		t.Fatalf("expected file cache with a dir, got %T", cache)
+	}
+
+	cfg.Disabled = true
+	if cache, err := newOCRCache(cfg); cache != nil || err != nil {
+		t.Fatalf("expected no cache when disabled, got %T %v", cache, err)
+	}
+}
+
+func TestAutoLanguages(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages *ocr.LanguageSet
+		want      []string
+	}{
+		{"unknown", nil, nil},
+		{"installed defaults", ocr.NewLanguageSet([]string{"chi_sim", "eng", "fra", "osd"}), []string{"eng", "chi_sim"}},
+		{"no defaults installed", ocr.NewLanguageSet([]string{"rus", "fra", "osd"}), []string{"fra", "rus"}},
+	}
+	for _, tt := range tests {
+		if got := autoLanguages(tt.languages); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: autoLanguages = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	cfg := config.Default().Server
+	if keyring, err := newKeyring(cfg); keyring != nil || err != nil {
+		t.Fatalf("expected no keyring without keys, got %v %v", keyring, err)
+	}
+
+	cfg.APIKeysFile = filepath.Join(t.TempDir(), "keys.yaml")
+	data := "keys:\n  - name: billing\n    hash: " + auth.Hash("billing-secret") + "\n    scopes: [ocr:read]\n"
+	if err := os.WriteFile(cfg.APIKeysFile, []byte(data), 0o600); err != nil {
+		t.Fatalf("write keys: %v", err)
+	}
+	cfg.APIKey = "root-secret"
+	keyring, err := newKeyring(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key, err := keyring.Authenticate("billing-secret"); err != nil || key.Name != "billing" {
+		t.Fatalf("expected file key, got %+v %v", key, err)
+	}
+	if key, err := keyring.Authenticate("root-secret"); err != nil || !key.Allows(auth.ScopeAdmin) {
+		t.Fatalf("expected API_KEY as the default admin key, got %+v %v", key, err)
+	}
+
+	cfg.APIKeysFile = filepath.Join(t.TempDir(), "missing.yaml")
+	if _, err := newKeyring(cfg); err == nil {
+		t.Fatal("expected a missing keys file to fail")
+	}
+}
+
+// startServe runs serve with handler on a local port and returns the server URL,
+// a function that triggers the shutdown, and serve's result.
+func startServe(t *testing.T, h http.HandlerFunc, drainTimeout time.Duration) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+	manager, err := job.NewManager(job.NewMemoryStore(), func(ctx context.Context, inputPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+		return nil, nil
+	}, job.Config{DataDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("new job manager: %v", err)
+	}
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("start job manager: %v", err)
+	}
+	t.Cleanup(manager.Close)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	t.Cleanup(cancelRequests)
+	srv := &http.Server{
+		Handler:     h,
+		BaseContext: func(net.Listener) context.Context { return requestsCtx },
+	}
+
+	ctx, shutdown := context.WithCancel(context.Background())
+	t.Cleanup(shutdown)
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, srv, ln, manager, drainTimeout, cancelRequests)
+	}()
+	return "http://" + ln.Addr().String(), shutdown, done
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	url, shutdown, done := startServe(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "finished")
+	}, 5*time.Second)
+
+	result := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		result <- string(body)
+	}()
+	<-started
+	shutdown()
+
+	if got := <-result; got != "finished" {
+		t.Fatalf("expected in-flight request to finish, got %q", got)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after draining")
+	}
+
+	// New connections are refused after shutdown
+	if _, err := http.Get(url); err == nil {
+		t.Fatal("expected new requests to be refused")
+	}
+}
+
+func TestServe_CancelsRequestsAfterDrainDeadline(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan error, 1)
+	url, shutdown, done := startServe(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		cancelled <- r.Context().Err()
+	}, 50*time.Millisecond)
+
+	go func() {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	shutdown()
+
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected request context cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request was not cancelled after the drain deadline")
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return")
+	}
+}
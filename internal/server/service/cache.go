@@ -0,0 +1,176 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"app/internal/ocr"
+	"app/pkg"
+)
+
+// Cache stores OCR results by content key.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]ocr.PageContent, bool, error)
+	Set(ctx context.Context, key string, pages []ocr.PageContent) error
+}
+
+// CacheStatus reports how a request was served with respect to the cache.
+type CacheStatus string
+
+const (
+	CacheHit    CacheStatus = "hit"
+	CacheMiss   CacheStatus = "miss"
+	CacheBypass CacheStatus = "bypass" // The cache was skipped for this request
+)
+
+// CacheKey derives the cache key of a document from the SHA-256 of its bytes and
+// the options that influence the result.
+func CacheKey(sum []byte, opts ocr.Options) string {
+	normalization := opts.Normalization
+	if normalization == "" {
+		normalization = pkg.NormalizationCollapsed
+	}
+	pages := opts.Pages
+	if selection, err := ocr.ParsePageSet(pages); err == nil {
+		pages = selection.String()
+	}
+	// Only options that change the pages take part in the key
+	params, _ := json.Marshal(struct {
+		Language        string            `json:"language"`
+		TextThreshold   int               `json:"text_threshold"`
+		ForceOCR        bool              `json:"force_ocr"`
+		RemoveWatermark bool              `json:"remove_watermark"`
+		Layout          bool              `json:"layout"`
+		Correct         bool              `json:"correct,omitempty"`
+		Normalization   pkg.Normalization `json:"normalization"`
+		Pages           string            `json:"pages,omitempty"`
+		Password        string            `json:"password,omitempty"` // Decrypted results are only served to holders of the password
+	}{
+		Language:        opts.Language,
+		TextThreshold:   max(opts.TextThreshold, 0),
+		ForceOCR:        opts.ForceOCR,
+		RemoveWatermark: opts.RemoveWatermark,
+		Layout:          opts.Layout,
+		Correct:         opts.Correct,
+		Normalization:   normalization,
+		Pages:           pages,
+		Password:        opts.Password,
+	})
+
+	h := sha256.New()
+	h.Write(sum)
+	h.Write(params)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type bypassKey struct{}
+
+// WithoutCache returns a context under which OCRService skips cache lookups.
+// Fresh results still replace the cached ones.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// CacheBypassed reports whether ctx was derived from WithoutCache.
+func CacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
+
+type statusKey struct{}
+
+// WithCacheStatus returns a context under which OCRService passes the request's cache
+// status to report as soon as it is known, before any progress is reported. Streaming
+// responses use it to send the status as a header.
+func WithCacheStatus(ctx context.Context, report func(CacheStatus)) context.Context {
+	return context.WithValue(ctx, statusKey{}, report)
+}
+
+// ReportCacheStatus passes status to the function ctx was derived with in WithCacheStatus, if any.
+func ReportCacheStatus(ctx context.Context, status CacheStatus) {
+	if report, ok := ctx.Value(statusKey{}).(func(CacheStatus)); ok {
+		report(status)
+	}
+}
+
+// MemoryCache is an in-memory LRU Cache.
+type MemoryCache struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	pages   []ocr.PageContent
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxEntries results (default: 256)
+// that expire after ttl without use (zero keeps them until evicted).
+func NewMemoryCache(maxEntries int, ttl time.Duration) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = 256
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached pages for key.
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]ocr.PageContent, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+	c.order.MoveToFront(elem)
+	return append([]ocr.PageContent(nil), entry.pages...), true, nil
+}
+
+// Set stores pages under key, evicting the least recently used results beyond the size limit.
+func (c *MemoryCache) Set(ctx context.Context, key string, pages []ocr.PageContent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{key: key, pages: append([]ocr.PageContent(nil), pages...)}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"app/internal/ocr"
+	"app/pkg"
+)
+
+func TestCacheKey(t *testing.T) {
+	sum := []byte("document hash")
+	base := CacheKey(sum, ocr.Options{Language: "eng"})
+
+	same := []ocr.Options{
+		{Language: "eng", Normalization: pkg.NormalizationCollapsed},
+		{Language: "eng", Concurrency: 8},
+		{Language: "eng", TextThreshold: -1},
+		{Language: "eng", Pages: " "},
+	}
+	for _, opts := range same {
+		if got := CacheKey(sum, opts); got != base {
+			t.Errorf("expected %+v to share the key of the defaults", opts)
+		}
+	}
+
+	different := []ocr.Options{
+		{Language: "ind"},
+		{Language: "eng", TextThreshold: 10},
+		{Language: "eng", ForceOCR: true},
+		{Language: "eng", RemoveWatermark: true},
+		{Language: "eng", Layout: true},
+		{Language: "eng", Normalization: pkg.NormalizationLines},
+		{Language: "eng", Pages: "1-2"},
+		{Language: "eng", Password: "secret"},
+	}
+	for _, opts := range different {
+		if got := CacheKey(sum, opts); got == base {
+			t.Errorf("expected %+v to change the key", opts)
+		}
+	}
+
+	if CacheKey(sum, ocr.Options{Pages: "1 - 3, last"}) != CacheKey(sum, ocr.Options{Pages: "1-3,last"}) {
+		t.Error("expected equivalent page selections to share a key")
+	}
+
+	if CacheKey([]byte("other document"), ocr.Options{Language: "eng"}) == base {
+		t.Error("expected different documents to have different keys")
+	}
+}
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2, 0)
+
+	cache.Set(ctx, "a", []ocr.PageContent{{Page: 1, Content: "a"}})
+	cache.Set(ctx, "b", []ocr.PageContent{{Page: 1, Content: "b"}})
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.Set(ctx, "c", []ocr.PageContent{{Page: 1, Content: "c"}})
+
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		pages, ok, _ := cache.Get(ctx, key)
+		if !ok || pages[0].Content != key {
+			t.Errorf("expected %s to be cached, got %+v", key, pages)
+		}
+	}
+}
+
+func TestMemoryCache_Expires(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(0, 20*time.Millisecond)
+
+	cache.Set(ctx, "a", []ocr.PageContent{{Page: 1}})
+	time.Sleep(40 * time.Millisecond)
+
+	if _, ok, _ := cache.Get(ctx, "a"); ok {
+		t.Fatal("expected entry to expire")
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("new file cache: %v", err)
+	}
+
+	key := CacheKey([]byte("doc"), ocr.Options{})
+	if _, ok, err := cache.Get(ctx, key); ok || err != nil {
+		t.Fatalf("expected miss, got ok=%v err=%v", ok, err)
+	}
+
+	pages := []ocr.PageContent{{Page: 2, Content: "cached", Source: ocr.SourceOCR}}
+	if err := cache.Set(ctx, key, pages); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	// A second instance over the same directory sees the entry
+	reopened, err := NewFileCache(dir, 0, 0)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	got, ok, err := reopened.Get(ctx, key)
+	if err != nil || !ok || len(got) != 1 || got[0].Content != "cached" || got[0].Source != ocr.SourceOCR {
+		t.Fatalf("unexpected entry: %+v ok=%v err=%v", got, ok, err)
+	}
+
+	if err := cache.Set(ctx, "../escape", pages); err == nil {
+		t.Fatal("expected invalid key to be rejected")
+	}
+}
+
+func TestFileCache_EvictsBeyondMaxBytes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	pages := []ocr.PageContent{{Page: 1, Content: "some text"}}
+
+	// Room for two entries
+	probe, _ := NewFileCache(t.TempDir(), 0, 0)
+	probe.Set(ctx, "00", pages)
+	info, err := os.Stat(filepath.Join(probe.dir, "00.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewFileCache(dir, 2*info.Size(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	cache.Set(ctx, "aa", pages)
+	os.Chtimes(filepath.Join(dir, "aa.json"), old, old)
+	cache.Set(ctx, "bb", pages)
+	cache.Set(ctx, "cc", pages)
+
+	if _, ok, _ := cache.Get(ctx, "aa"); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+	for _, key := range []string{"bb", "cc"} {
+		if _, ok, _ := cache.Get(ctx, key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+}
+
+func TestFileCache_Expires(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cache, err := NewFileCache(dir, 0, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Set(ctx, "aa", []ocr.PageContent{{Page: 1}})
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "aa.json"), old, old)
+
+	if _, ok, _ := cache.Get(ctx, "aa"); ok {
+		t.Fatal("expected entry to expire")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "aa.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected expired entry removed, got %v", err)
+	}
+}
+
+func TestOCRService_Cache(t *testing.T) {
+	ctx := context.Background()
+	proc := &fakeProcessor{pages: []ocr.PageContent{{Page: 1, Content: expectedOCRText}}}
+	svc := NewOCRServiceWithCache(proc, NewMemoryCache(0, 0))
+
+	process := func(ctx context.Context, data string, opts ocr.Options) Result {
+		t.Helper()
+		file := &memoryFile{Reader: bytes.NewReader([]byte(data))}
+		result, err := svc.Process(ctx, file, &multipart.FileHeader{Filename: "doc.pdf"}, opts)
+		if err != nil {
+			t.Fatalf("process: %v", err)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		data   string
+		opts   ocr.Options
+		status CacheStatus
+		calls  int
+	}{
+		{"first run", ctx, "%PDF-a", ocr.Options{Language: "eng"}, CacheMiss, 1},
+		{"same document", ctx, "%PDF-a", ocr.Options{Language: "eng"}, CacheHit, 1},
+		{"different options", ctx, "%PDF-a", ocr.Options{Language: "ind"}, CacheMiss, 2},
+		{"different document", ctx, "%PDF-b", ocr.Options{Language: "eng"}, CacheMiss, 3},
+		{"bypass", WithoutCache(ctx), "%PDF-a", ocr.Options{Language: "eng"}, CacheBypass, 4},
+		{"searchable pdf", ctx, "%PDF-a", ocr.Options{Language: "eng", SearchablePDF: filepath.Join(t.TempDir(), "out.pdf")}, CacheBypass, 5},
+	}
+	for _, tt := range tests {
+		var reported CacheStatus
+		result := process(WithCacheStatus(tt.ctx, func(status CacheStatus) { reported = status }), tt.data, tt.opts)
+		if result.Cache != tt.status || proc.calls != tt.calls {
+			t.Errorf("%s: got status %q after %d calls, want %q after %d", tt.name, result.Cache, proc.calls, tt.status, tt.calls)
+		}
+		if reported != tt.status {
+			t.Errorf("%s: reported status %q, want %q", tt.name, reported, tt.status)
+		}
+		if len(result.Pages) != 1 || result.Pages[0].Content != expectedOCRText {
+			t.Errorf("%s: unexpected pages %+v", tt.name, result.Pages)
+		}
+	}
+}
+
+func TestOCRService_ProcessFileUsesCache(t *testing.T) {
+	ctx := context.Background()
+	proc := &fakeProcessor{pages: []ocr.PageContent{{Page: 1, Content: expectedOCRText}}}
+	svc := NewOCRServiceWithCache(proc, NewMemoryCache(0, 0))
+
+	path := filepath.Join(t.TempDir(), "doc.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// An upload of the same bytes is served from the entry the file run stored
+	if _, err := svc.ProcessFile(ctx, path, ocr.Options{}); err != nil {
+		t.Fatalf("process file: %v", err)
+	}
+	file := &memoryFile{Reader: bytes.NewReader([]byte("%PDF-a"))}
+	result, err := svc.Process(ctx, file, &multipart.FileHeader{Filename: "doc.pdf"}, ocr.Options{})
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if result.Cache != CacheHit || proc.calls != 1 {
+		t.Fatalf("expected cache hit, got %q after %d calls", result.Cache, proc.calls)
+	}
+}
+
+func TestOCRService_CacheHitReplaysProgress(t *testing.T) {
+	ctx := context.Background()
+	proc := &fakeProcessor{pages: []ocr.PageContent{{Page: 2, Content: "a"}, {Page: 5, Content: "b"}}}
+	svc := NewOCRServiceWithCache(proc, NewMemoryCache(0, 0))
+
+	var events []ocr.Progress
+	opts := ocr.Options{OnProgress: func(p ocr.Progress) { events = append(events, p) }}
+	for range 2 {
+		file := &memoryFile{Reader: bytes.NewReader([]byte("%PDF-a"))}
+		if _, err := svc.Process(ctx, file, &multipart.FileHeader{Filename: "doc.pdf"}, opts); err != nil {
+			t.Fatalf("process: %v", err)
+		}
+	}
+
+	// The fake processor reports nothing, so every event comes from the replayed hit
+	if len(events) != 3 || events[0].Page != nil || events[2].Done != 2 || events[2].Total != 2 || events[2].Page.Page != 5 {
+		t.Fatalf("unexpected progress events: %+v", events)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"log"
+	"unicode/utf8"
+
+	"app/internal/ocr"
+)
+
+// Corrector rewrites recognized page text to fix OCR errors, typically with an LLM.
+// Pages of a document are corrected concurrently.
+type Corrector interface {
+	Correct(ctx context.Context, text, language string) (string, error)
+}
+
+// DefaultCorrectionConfidence is the mean line confidence below which layout pages are corrected.
+const DefaultCorrectionConfidence = 90
+
+// EnableCorrection turns on LLM post-correction for requests with Options.Correct.
+// OCRed pages are sent to corrector unless they carry layout lines with a mean
+// confidence of at least minConfidence (default: DefaultCorrectionConfidence).
+// Text layer pages are never corrected.
+func (s *OCRService) EnableCorrection(corrector Corrector, minConfidence float64) {
+	if minConfidence <= 0 {
+		minConfidence = DefaultCorrectionConfidence
+	}
+	s.corrector = corrector
+	s.minConfidence = minConfidence
+}
+
+// withCorrection sets opts.PostProcess so every page is corrected in its OCR worker as it
+// finishes, in parallel with the other pages, before it is reported and returned.
+func (s *OCRService) withCorrection(opts ocr.Options) ocr.Options {
+	if !opts.Correct || s.corrector == nil {
+		return opts
+	}
+	opts.PostProcess = s.correctPage
+	return opts
+}
+
+// correctPage corrects page if it needs it, keeping the recognized text in RawContent.
+// Failures are logged and leave the raw text in place.
+func (s *OCRService) correctPage(ctx context.Context, page ocr.PageContent) ocr.PageContent {
+	if page.Source != ocr.SourceOCR || page.Content == "" || meanConfidence(page.Lines) >= s.minConfidence {
+		page.Correction = ocr.CorrectionSkipped
+		return page
+	}
+
+	text, err := s.corrector.Correct(ctx, page.Content, page.Language)
+	if err != nil {
+		log.Printf("llm correction: page %d: %v", page.Page, err)
+		page.Correction = ocr.CorrectionFailed
+		return page
+	}
+	page.RawContent = page.Content
+	page.Content = text
+	page.Chars = utf8.RuneCountInString(text)
+	page.Correction = ocr.CorrectionApplied
+	return page
+}
+
+// meanConfidence returns the mean confidence of lines, or -1 when there are none.
+func meanConfidence(lines []ocr.Line) float64 {
+	if len(lines) == 0 {
+		return -1
+	}
+	var total float64
+	for _, line := range lines {
+		total += line.Confidence
+	}
+	return total / float64(len(lines))
+}
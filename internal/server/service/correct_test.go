@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app/internal/llm"
+	"app/internal/ocr"
+)
+
+// progressProcessor returns pages, post-processing and reporting each of them through
+// OnProgress, like ocr.Processor.
+type progressProcessor struct {
+	pages []ocr.PageContent
+}
+
+func (p *progressProcessor) ExtractText(ctx context.Context, pdfPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+	pages := append([]ocr.PageContent(nil), p.pages...)
+	if opts.OnProgress != nil {
+		opts.OnProgress(ocr.Progress{Total: len(pages)})
+	}
+	for i := range pages {
+		if opts.PostProcess != nil {
+			pages[i] = opts.PostProcess(ctx, pages[i])
+		}
+		if opts.OnProgress != nil {
+			page := pages[i]
+			opts.OnProgress(ocr.Progress{Done: i + 1, Total: len(pages), Page: &page})
+		}
+	}
+	return pages, nil
+}
+
+type stubCorrector struct {
+	err   error
+	texts []string
+}
+
+func (s *stubCorrector) Correct(ctx context.Context, text, language string) (string, error) {
+	s.texts = append(s.texts, text)
+	if s.err != nil {
+		return "", s.err
+	}
+	return strings.ReplaceAll(text, "0", "o"), nil
+}
+
+func TestOCRService_Correction(t *testing.T) {
+	proc := &progressProcessor{pages: []ocr.PageContent{
+		{Page: 1, Content: "text layer", Source: ocr.SourceTextLayer},
+		{Page: 2, Content: "hell0 w0rld", Source: ocr.SourceOCR, Chars: 11},
+		{Page: 3, Content: "confident", Source: ocr.SourceOCR, Lines: []ocr.Line{{Confidence: 96}}},
+		{Page: 4, Content: "d0ubtful", Source: ocr.SourceOCR, Lines: []ocr.Line{{Confidence: 60}}},
+	}}
+	corrector := &stubCorrector{}
+	svc := NewOCRService(proc)
+	svc.EnableCorrection(corrector, 0)
+
+	var streamed []string
+	opts := ocr.Options{Correct: true, OnProgress: func(p ocr.Progress) {
+		if p.Page != nil {
+			streamed = append(streamed, p.Page.Content)
+		}
+	}}
+	pages, err := svc.ProcessFile(context.Background(), "doc.pdf", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		content, raw, correction string
+	}{
+		{"text layer", "", ocr.CorrectionSkipped},
+		{"hello world", "hell0 w0rld", ocr.CorrectionApplied},
+		{"confident", "", ocr.CorrectionSkipped},
+		{"doubtful", "d0ubtful", ocr.CorrectionApplied},
+	}
+	for i, want := range expected {
+		page := pages[i]
+		if page.Content != want.content || page.RawContent != want.raw || page.Correction != want.correction {
+			t.Fatalf("page %d: unexpected %+v", page.Page, page)
+		}
+		if streamed[i] != want.content {
+			t.Fatalf("page %d: expected corrected text in progress, got %q", page.Page, streamed[i])
+		}
+	}
+	if pages[1].Chars != 11 || len(corrector.texts) != 2 {
+		t.Fatalf("expected two corrections, got %v", corrector.texts)
+	}
+
+	// Without the option the pages are left alone
+	pages, _ = svc.ProcessFile(context.Background(), "doc.pdf", ocr.Options{})
+	if pages[1].Content != "hell0 w0rld" || pages[1].Correction != "" || len(corrector.texts) != 2 {
+		t.Fatalf("expected no correction without the option, got %+v", pages[1])
+	}
+}
+
+func TestOCRService_CorrectionFailureKeepsRawText(t *testing.T) {
+	proc := &progressProcessor{pages: []ocr.PageContent{{Page: 1, Content: "hell0", Source: ocr.SourceOCR}}}
+	svc := NewOCRService(proc)
+	svc.EnableCorrection(&stubCorrector{err: errors.New("model not found")}, 0)
+
+	pages, err := svc.ProcessFile(context.Background(), "doc.pdf", ocr.Options{Correct: true})
+	if err != nil {
+		t.Fatalf("expected correction failures not to fail the request, got %v", err)
+	}
+	if pages[0].Content != "hell0" || pages[0].RawContent != "" || pages[0].Correction != ocr.CorrectionFailed {
+		t.Fatalf("unexpected page: %+v", pages[0])
+	}
+}
+
+func TestOCRService_CorrectionWithOllama(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(map[string]any{"response": "Invoice 2024-001\n", "done": true})
+	}))
+	defer server.Close()
+
+	proc := &progressProcessor{pages: []ocr.PageContent{{Page: 1, Content: "Inv0ice 2O24-001", Source: ocr.SourceOCR, Language: "eng"}}}
+	svc := NewOCRService(proc)
+	svc.EnableCorrection(llm.NewCorrector(llm.NewOllama(server.URL, "llama3", time.Second)), 0)
+
+	pages, err := svc.ProcessFile(context.Background(), "doc.pdf", ocr.Options{Correct: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pages[0].Content != "Invoice 2024-001" || pages[0].RawContent != "Inv0ice 2O24-001" {
+		t.Fatalf("unexpected page: %+v", pages[0])
+	}
+	if !strings.Contains(prompt, "Inv0ice 2O24-001") || !strings.Contains(prompt, "eng") {
+		t.Fatalf("expected page text and language in prompt, got %q", prompt)
+	}
+}
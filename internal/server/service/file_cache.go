@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"app/internal/ocr"
+)
+
+// FileCache is a Cache that keeps one JSON file per result in a directory.
+// Reads refresh a file's modification time, so eviction is least recently used
+// and entries expire ttl after they were last used.
+type FileCache struct {
+	dir      string
+	maxBytes int64
+	ttl      time.Duration
+
+	mu sync.Mutex
+}
+
+// NewFileCache creates a FileCache in dir holding up to maxBytes of results (zero for no limit)
+// that expire after ttl without use (zero keeps them until evicted).
+func NewFileCache(dir string, maxBytes int64, ttl time.Duration) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	return &FileCache{dir: dir, maxBytes: maxBytes, ttl: ttl}, nil
+}
+
+// Get returns the cached pages for key.
+func (c *FileCache) Get(ctx context.Context, key string) ([]ocr.PageContent, bool, error) {
+	path, ok := c.path(key)
+	if !ok {
+		return nil, false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("stat cache entry: %w", err)
+	}
+	if c.expired(info) {
+		os.Remove(path)
+		return nil, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("read cache entry: %w", err)
+	}
+	var pages []ocr.PageContent
+	if err := json.Unmarshal(data, &pages); err != nil {
+		// A corrupt entry is a miss; it is replaced on the next Set
+		os.Remove(path)
+		return nil, false, nil
+	}
+
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return pages, true, nil
+}
+
+// Set stores pages under key, evicting expired and least recently used results beyond the size limit.
+func (c *FileCache) Set(ctx context.Context, key string, pages []ocr.PageContent) error {
+	path, ok := c.path(key)
+	if !ok {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+
+	data, err := json.Marshal(pages)
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("create cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("store cache entry: %w", err)
+	}
+
+	return c.evict()
+}
+
+// evict removes expired entries and then the oldest ones until the cache fits maxBytes.
+func (c *FileCache) evict() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("list cache: %w", err)
+	}
+
+	var (
+		files []fs.FileInfo
+		total int64
+	)
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if c.expired(info) {
+			os.Remove(filepath.Join(c.dir, info.Name()))
+			continue
+		}
+		files = append(files, info)
+		total += info.Size()
+	}
+
+	if c.maxBytes <= 0 {
+		return nil
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, info := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		os.Remove(filepath.Join(c.dir, info.Name()))
+		total -= info.Size()
+	}
+	return nil
+}
+
+func (c *FileCache) expired(info fs.FileInfo) bool {
+	return c.ttl > 0 && time.Since(info.ModTime()) > c.ttl
+}
+
+// path maps a key to its entry file. Keys must be hex so they cannot escape the directory.
+func (c *FileCache) path(key string) (string, bool) {
+	if _, err := hex.DecodeString(key); err != nil || key == "" {
+		return "", false
+	}
+	return filepath.Join(c.dir, key+".json"), true
+}
@@ -2,9 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
+	"io"
+	"log"
 	"mime/multipart"
+	"os"
 
+	"app/internal/metrics"
 	"app/internal/ocr"
 )
 
@@ -13,9 +18,19 @@ type Processor interface {
 	ExtractText(ctx context.Context, pdfPath string, opts ocr.Options) ([]ocr.PageContent, error)
 }
 
+// Result is the outcome of an OCR request.
+type Result struct {
+	Pages []ocr.PageContent
+	Cache CacheStatus // Empty when the service has no cache
+}
+
 // OCRService orchestrates OCR processing.
 type OCRService struct {
 	processor Processor
+	cache     Cache
+
+	corrector     Corrector // Set by EnableCorrection
+	minConfidence float64
 }
 
 // NewOCRService creates OCRService.
@@ -23,17 +38,140 @@ func NewOCRService(proc Processor) *OCRService {
 	return &OCRService{processor: proc}
 }
 
+// NewOCRServiceWithCache creates an OCRService that reuses results for documents
+// it has already processed with the same options.
+func NewOCRServiceWithCache(proc Processor, cache Cache) *OCRService {
+	return &OCRService{processor: proc, cache: cache}
+}
+
 // Process persists the uploaded file and runs OCR.
-func (s *OCRService) Process(ctx context.Context, file multipart.File, header *multipart.FileHeader, lang string) ([]ocr.PageContent, error) {
-	tempPath, cleanup, err := ocr.SaveUploadedFile(file)
+func (s *OCRService) Process(ctx context.Context, file multipart.File, header *multipart.FileHeader, opts ocr.Options) (Result, error) {
+	hash := sha256.New()
+	tempPath, cleanup, err := ocr.SaveUploadedFile(io.TeeReader(file, hash))
 	if err != nil {
-		return nil, fmt.Errorf("persist upload (%s): %w", header.Filename, err)
+		return Result{}, fmt.Errorf("persist upload (%s): %w", header.Filename, err)
 	}
 	defer cleanup()
 
-	pages, err := s.processor.ExtractText(ctx, tempPath, ocr.Options{Language: lang})
+	return s.processCached(ctx, hash.Sum(nil), tempPath, opts)
+}
+
+// ProcessFile runs OCR on a PDF already stored on disk.
+func (s *OCRService) ProcessFile(ctx context.Context, pdfPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+	var sum []byte
+	if s.cache != nil {
+		var err error
+		if sum, err = fileSum(pdfPath); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.processCached(ctx, sum, pdfPath, opts)
 	if err != nil {
 		return nil, err
 	}
-	return pages, nil
+	return result.Pages, nil
+}
+
+// ProcessImage persists an uploaded image, wraps it into a PDF and runs OCR.
+func (s *OCRService) ProcessImage(ctx context.Context, file multipart.File, header *multipart.FileHeader, opts ocr.Options) (Result, error) {
+	hash := sha256.New()
+	imagePath, _, cleanupImage, err := ocr.SaveUploadedImage(io.TeeReader(file, hash))
+	if err != nil {
+		return Result{}, fmt.Errorf("persist upload (%s): %w", header.Filename, err)
+	}
+	defer cleanupImage()
+
+	pdfPath, cleanupPDF, err := ocr.ImageToPDF(imagePath)
+	if err != nil {
+		return Result{}, fmt.Errorf("convert image (%s): %w", header.Filename, err)
+	}
+	defer cleanupPDF()
+
+	// Images never carry a text layer, so skip straight to OCR
+	opts.ForceOCR = true
+	return s.processCached(ctx, hash.Sum(nil), pdfPath, opts)
+}
+
+// processCached runs OCR on pdfPath, serving and storing results in the cache under
+// the key derived from sum, the SHA-256 of the uploaded bytes.
+func (s *OCRService) processCached(ctx context.Context, sum []byte, pdfPath string, opts ocr.Options) (Result, error) {
+	if s.cache == nil {
+		pages, err := s.extract(ctx, pdfPath, opts)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Pages: pages}, nil
+	}
+
+	// Searchable PDFs are written by the run itself and cannot be replayed from the cache
+	if opts.SearchablePDF != "" {
+		ReportCacheStatus(ctx, CacheBypass)
+		pages, err := s.extract(ctx, pdfPath, opts)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Pages: pages, Cache: CacheBypass}, nil
+	}
+
+	key := CacheKey(sum, opts)
+	status := CacheBypass
+	if !CacheBypassed(ctx) {
+		status = CacheMiss
+		pages, ok, err := s.cache.Get(ctx, key)
+		if err != nil {
+			log.Printf("ocr cache: get %s: %v", key, err)
+		}
+		if ok {
+			ReportCacheStatus(ctx, CacheHit)
+			replayProgress(pages, opts.OnProgress)
+			return Result{Pages: pages, Cache: CacheHit}, nil
+		}
+	}
+
+	ReportCacheStatus(ctx, status)
+	pages, err := s.extract(ctx, pdfPath, opts)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := s.cache.Set(ctx, key, pages); err != nil {
+		log.Printf("ocr cache: set %s: %v", key, err)
+	}
+	return Result{Pages: pages, Cache: status}, nil
+}
+
+// extract runs the processor and, when requested, LLM correction, counting the run as
+// an in-flight job while it lasts.
+func (s *OCRService) extract(ctx context.Context, pdfPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+	metrics.JobsInFlight.Inc()
+	defer metrics.JobsInFlight.Dec()
+
+	return s.processor.ExtractText(ctx, pdfPath, s.withCorrection(opts))
+}
+
+// replayProgress reports cached pages through onProgress as if they had just been processed.
+func replayProgress(pages []ocr.PageContent, onProgress func(ocr.Progress)) {
+	if onProgress == nil {
+		return
+	}
+	onProgress(ocr.Progress{Total: len(pages)})
+	for i := range pages {
+		page := pages[i]
+		onProgress(ocr.Progress{Done: i + 1, Total: len(pages), Page: &page})
+	}
+}
+
+// fileSum returns the SHA-256 of the file at path.
+func fileSum(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open input: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return nil, fmt.Errorf("hash input: %w", err)
+	}
+	return hash.Sum(nil), nil
 }
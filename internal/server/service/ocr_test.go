@@ -1,8 +1,11 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/png"
 	"io"
 	"mime/multipart"
 	"os"
@@ -20,9 +23,11 @@ type fakeProcessor struct {
 	err      error
 	lastPath string
 	lastOpts ocr.Options
+	calls    int
 }
 
 func (f *fakeProcessor) ExtractText(ctx context.Context, pdfPath string, opts ocr.Options) ([]ocr.PageContent, error) {
+	f.calls++
 	f.lastPath = pdfPath
 	f.lastOpts = opts
 	if f.err != nil {
@@ -39,15 +44,18 @@ func TestOCRService_Process_Success(t *testing.T) {
 
 	file, header := sampleUploadFile(t)
 
-	pages, err := svc.Process(context.Background(), file, header, "eng")
+	result, err := svc.Process(context.Background(), file, header, ocr.Options{Language: "eng", Layout: true})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(pages) != 1 || pages[0].Content != expectedOCRText {
-		t.Fatalf("unexpected pages: %+v", pages)
+	if len(result.Pages) != 1 || result.Pages[0].Content != expectedOCRText {
+		t.Fatalf("unexpected pages: %+v", result.Pages)
 	}
-	if proc.lastOpts.Language != "eng" {
-		t.Fatalf("expected language to pass through, got %s", proc.lastOpts.Language)
+	if result.Cache != "" {
+		t.Fatalf("expected no cache status without a cache, got %q", result.Cache)
+	}
+	if proc.lastOpts.Language != "eng" || !proc.lastOpts.Layout {
+		t.Fatalf("expected options to pass through, got %+v", proc.lastOpts)
 	}
 	if proc.lastPath == "" {
 		t.Fatal("expected pdf path to be captured")
@@ -64,12 +72,59 @@ func TestOCRService_Process_PropagatesProcessorError(t *testing.T) {
 
 	file, header := sampleUploadFile(t)
 
-	_, err := svc.Process(context.Background(), file, header, "")
+	_, err := svc.Process(context.Background(), file, header, ocr.Options{})
 	if !errors.Is(err, wantErr) {
 		t.Fatalf("expected %v, got %v", wantErr, err)
 	}
 }
 
+func TestOCRService_ProcessImage(t *testing.T) {
+	proc := &fakeProcessor{
+		pages: []ocr.PageContent{{Page: 1, Content: expectedOCRText}},
+	}
+	svc := NewOCRService(proc)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	file := &memoryFile{Reader: bytes.NewReader(buf.Bytes())}
+	header := &multipart.FileHeader{Filename: "receipt.png", Size: int64(buf.Len())}
+
+	result, err := svc.ProcessImage(context.Background(), file, header, ocr.Options{Language: "eng"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Pages) != 1 || result.Pages[0].Content != expectedOCRText {
+		t.Fatalf("unexpected pages: %+v", result.Pages)
+	}
+	if !proc.lastOpts.ForceOCR || proc.lastOpts.Language != "eng" {
+		t.Fatalf("expected forced OCR with language, got %+v", proc.lastOpts)
+	}
+	if _, err := os.Stat(proc.lastPath); !os.IsNotExist(err) {
+		t.Fatalf("expected converted pdf cleanup, got err=%v", err)
+	}
+}
+
+func TestOCRService_ProcessImage_Unsupported(t *testing.T) {
+	svc := NewOCRService(&fakeProcessor{})
+
+	file := &memoryFile{Reader: bytes.NewReader([]byte("%PDF-1.4"))}
+	header := &multipart.FileHeader{Filename: "doc.pdf"}
+
+	_, err := svc.ProcessImage(context.Background(), file, header, ocr.Options{Language: "eng"})
+	if !errors.Is(err, ocr.ErrUnsupportedImage) {
+		t.Fatalf("expected ErrUnsupportedImage, got %v", err)
+	}
+}
+
+// memoryFile adapts an in-memory reader to multipart.File.
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (m *memoryFile) Close() error { return nil }
+
 func sampleUploadFile(t *testing.T) (*os.File, *multipart.FileHeader) {
 	t.Helper()
 	src, err := os.Open(samplePDFPath(t))
@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, formatted as "sha256=<hex>".
+const SignatureHeader = "X-OCR-Signature-256"
+
+var (
+	// ErrInvalidURL is returned for callback URLs that are not absolute http(s) URLs.
+	ErrInvalidURL = errors.New("invalid callback url")
+	// ErrNonPublicAddress is returned for callbacks to loopback, private, link-local and other
+	// non-public addresses outside Notifier.AllowedNetworks.
+	ErrNonPublicAddress = errors.New("callback address is not public")
+)
+
+// nonPublicNetworks are reserved ranges that netip does not already classify as non-public.
+var nonPublicNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // Reserved
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which can reach private IPv4 addresses
+}
+
+// Attempt records the outcome of a single delivery attempt.
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Notifier delivers signed JSON payloads to callback URLs, retrying with exponential backoff.
+// Callbacks only reach public addresses and AllowedNetworks, so job submitters cannot make
+// the service call internal endpoints.
+type Notifier struct {
+	// Client sends deliveries (default: one that dials only allowed addresses, without
+	// proxies). A custom Client skips the dial-time address check.
+	Client          *http.Client
+	AllowedNetworks []netip.Prefix // Non-public networks callbacks may reach, such as an internal gateway
+	MaxAttempts     int            // Total attempts including the first (default: 5)
+	InitialBackoff  time.Duration  // Wait before the first retry, doubled after each (default: 1s)
+	MaxBackoff      time.Duration  // Upper bound for the wait between retries (default: 1m)
+
+	clientOnce    sync.Once
+	defaultClient *http.Client
+}
+
+// NewNotifier returns a Notifier with sane defaults.
+func NewNotifier() *Notifier {
+	return &Notifier{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+}
+
+// CheckURL validates rawURL like ValidateURL and checks that every address its host
+// resolves to may be reached. Deliveries check the address they dial again, so a host
+// that later resolves elsewhere still cannot reach internal services.
+func (n *Notifier) CheckURL(ctx context.Context, rawURL string) error {
+	if err := ValidateURL(rawURL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(rawURL)
+	host := u.Hostname()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: resolve %s: %v", ErrInvalidURL, host, err)
+	}
+	for _, addr := range addrs {
+		if !n.allowed(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrNonPublicAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// allowed reports whether callbacks may reach addr.
+func (n *Notifier) allowed(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range n.AllowedNetworks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicNetworks {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// client returns Client, or the default client that refuses to connect to addresses
+// that are not allowed.
+func (n *Notifier) client() *http.Client {
+	if n.Client != nil {
+		return n.Client
+	}
+	n.clientOnce.Do(func() {
+		dialer := &net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				addrPort, err := netip.ParseAddrPort(address)
+				if err != nil {
+					return err
+				}
+				if !n.allowed(addrPort.Addr()) {
+					return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+				}
+				return nil
+			},
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = nil // The proxy would be dialed and checked instead of the callback host
+		transport.DialContext = dialer.DialContext
+		n.defaultClient = &http.Client{Timeout: 30 * time.Second, Transport: transport}
+	})
+	return n.defaultClient
+}
+
+// ValidateURL checks that rawURL is an absolute http or https URL.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, rawURL)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches body, in constant time.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Deliver POSTs payload to callbackURL until it is acknowledged with a 2xx status,
+// a non-retryable status is returned, attempts run out or ctx is done.
+// The body is signed when secret is non-empty. record is called after every attempt.
+func (n *Notifier) Deliver(ctx context.Context, callbackURL, secret string, payload []byte, record func(Attempt)) error {
+	maxAttempts := n.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	backoff := n.InitialBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	maxBackoff := n.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = time.Minute
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff = min(backoff*2, maxBackoff)
+		}
+
+		status, err := n.post(ctx, callbackURL, secret, payload)
+		a := Attempt{At: time.Now().UTC(), StatusCode: status}
+		if err != nil {
+			a.Error = err.Error()
+		}
+		if record != nil {
+			record(a)
+		}
+
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable(status) || errors.Is(err, ErrNonPublicAddress) {
+			break
+		}
+	}
+
+	return fmt.Errorf("deliver webhook: %w", lastErr)
+}
+
+// post sends a single delivery and returns the response status.
+func (n *Notifier) post(ctx context.Context, callbackURL, secret string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, payload))
+	}
+
+	resp, err := n.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryable reports whether a delivery that got status may succeed on retry.
+// Status 0 means the request never got a response.
+func retryable(status int) bool {
+	switch {
+	case status == 0:
+		return true
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return true
+	default:
+		return status >= 500
+	}
+}
@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"testing"
+	"time"
+)
+
+type receiver struct {
+	mu        sync.Mutex
+	responses []int
+	bodies    [][]byte
+	sigs      []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	r.sigs = append(r.sigs, req.Header.Get(SignatureHeader))
+
+	status := http.StatusOK
+	if n := len(r.bodies) - 1; n < len(r.responses) {
+		status = r.responses[n]
+	}
+	w.WriteHeader(status)
+}
+
+// loopback lets test notifiers reach httptest servers.
+var loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+
+func fastNotifier() *Notifier {
+	return &Notifier{AllowedNetworks: loopback, MaxAttempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestDeliver_SignsPayload(t *testing.T) {
+	recv := &receiver{}
+	ts := httptest.NewServer(recv)
+	defer ts.Close()
+
+	payload := []byte(`{"id":"job-1","status":"succeeded"}`)
+	var attempts []Attempt
+	err := fastNotifier().Deliver(context.Background(), ts.URL, "secret", payload, func(a Attempt) {
+		attempts = append(attempts, a)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(recv.bodies) != 1 || string(recv.bodies[0]) != string(payload) {
+		t.Fatalf("unexpected deliveries: %q", recv.bodies)
+	}
+	if !Verify("secret", payload, recv.sigs[0]) {
+		t.Fatalf("signature %q does not verify", recv.sigs[0])
+	}
+	if Verify("other", payload, recv.sigs[0]) {
+		t.Fatal("signature must not verify with another secret")
+	}
+	if len(attempts) != 1 || attempts[0].StatusCode != http.StatusOK || attempts[0].Error != "" {
+		t.Fatalf("unexpected attempts: %+v", attempts)
+	}
+}
+
+func TestDeliver_UnsignedWithoutSecret(t *testing.T) {
+	recv := &receiver{}
+	ts := httptest.NewServer(recv)
+	defer ts.Close()
+
+	if err := fastNotifier().Deliver(context.Background(), ts.URL, "", []byte(`{}`), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recv.sigs[0] != "" {
+		t.Fatalf("expected no signature, got %q", recv.sigs[0])
+	}
+}
+
+func TestDeliver_RetriesUntilAcknowledged(t *testing.T) {
+	recv := &receiver{responses: []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusNoContent}}
+	ts := httptest.NewServer(recv)
+	defer ts.Close()
+
+	var attempts []Attempt
+	err := fastNotifier().Deliver(context.Background(), ts.URL, "secret", []byte(`{}`), func(a Attempt) {
+		attempts = append(attempts, a)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %+v", attempts)
+	}
+	if attempts[0].StatusCode != 500 || attempts[0].Error == "" || attempts[2].StatusCode != 204 {
+		t.Fatalf("unexpected attempts: %+v", attempts)
+	}
+}
+
+func TestDeliver_GivesUp(t *testing.T) {
+	recv := &receiver{responses: []int{502, 502, 502, 502, 502}}
+	ts := httptest.NewServer(recv)
+	defer ts.Close()
+
+	var attempts []Attempt
+	err := fastNotifier().Deliver(context.Background(), ts.URL, "", []byte(`{}`), func(a Attempt) {
+		attempts = append(attempts, a)
+	})
+	if err == nil {
+		t.Fatal("expected error after exhausting attempts")
+	}
+	if len(attempts) != 4 {
+		t.Fatalf("expected 4 attempts, got %d", len(attempts))
+	}
+}
+
+func TestDeliver_StopsOnClientError(t *testing.T) {
+	recv := &receiver{responses: []int{http.StatusGone}}
+	ts := httptest.NewServer(recv)
+	defer ts.Close()
+
+	var attempts []Attempt
+	err := fastNotifier().Deliver(context.Background(), ts.URL, "", []byte(`{}`), func(a Attempt) {
+		attempts = append(attempts, a)
+	})
+	if err == nil || len(attempts) != 1 {
+		t.Fatalf("expected a single failed attempt, got %v %+v", err, attempts)
+	}
+}
+
+func TestDeliver_StopsOnContextCancel(t *testing.T) {
+	recv := &receiver{responses: []int{503, 503, 503, 503}}
+	ts := httptest.NewServer(recv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{AllowedNetworks: loopback, MaxAttempts: 4, InitialBackoff: time.Hour}
+	err := n.Deliver(ctx, ts.URL, "", []byte(`{}`), func(Attempt) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancelled, got %v", err)
+	}
+}
+
+func TestDeliver_RefusesNonPublicAddress(t *testing.T) {
+	recv := &receiver{}
+	ts := httptest.NewServer(recv)
+	defer ts.Close()
+
+	n := fastNotifier()
+	n.AllowedNetworks = nil
+	var attempts []Attempt
+	err := n.Deliver(context.Background(), ts.URL, "", []byte(`{}`), func(a Attempt) {
+		attempts = append(attempts, a)
+	})
+	if !errors.Is(err, ErrNonPublicAddress) || len(attempts) != 1 {
+		t.Fatalf("expected a single refused attempt, got %v %+v", err, attempts)
+	}
+	if len(recv.bodies) != 0 {
+		t.Fatal("expected nothing delivered to a loopback address")
+	}
+}
+
+func TestNotifier_CheckURL(t *testing.T) {
+	ctx := context.Background()
+	n := NewNotifier()
+	for _, u := range []string{"https://93.184.215.14/hook", "http://[2606:4700::1111]:8080/hook"} {
+		if err := n.CheckURL(ctx, u); err != nil {
+			t.Errorf("CheckURL(%q) unexpected error: %v", u, err)
+		}
+	}
+	for _, u := range []string{
+		"http://127.0.0.1:9000/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.10/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[fe80::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+		"http://100.64.0.1/hook",
+	} {
+		if err := n.CheckURL(ctx, u); !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("CheckURL(%q) expected ErrNonPublicAddress, got %v", u, err)
+		}
+	}
+	if err := n.CheckURL(ctx, "ftp://example.com"); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("expected ErrInvalidURL for a non-http url, got %v", err)
+	}
+
+	n.AllowedNetworks = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	if err := n.CheckURL(ctx, "http://10.1.2.3/hook"); err != nil {
+		t.Errorf("expected an allowed network to be reachable, got %v", err)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, u := range []string{"http://localhost:9000/hook", "https://example.com/a?b=c"} {
+		if err := ValidateURL(u); err != nil {
+			t.Errorf("ValidateURL(%q) unexpected error: %v", u, err)
+		}
+	}
+	for _, u := range []string{"", "example.com/hook", "ftp://example.com", "https://", "::"} {
+		if err := ValidateURL(u); !errors.Is(err, ErrInvalidURL) {
+			t.Errorf("ValidateURL(%q) expected ErrInvalidURL, got %v", u, err)
+		}
+	}
+}
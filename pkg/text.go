@@ -1,7 +1,169 @@
 package pkg
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization selects how extracted text is cleaned up by Normalize.
+type Normalization string
+
+const (
+	// NormalizationRaw keeps the text exactly as the engine produced it.
+	NormalizationRaw Normalization = "raw"
+	// NormalizationLines keeps every line and its spacing (tables, columns) but trims
+	// trailing whitespace and squeezes runs of blank lines.
+	NormalizationLines Normalization = "lines"
+	// NormalizationParagraphs joins wrapped lines into paragraphs separated by a blank line
+	// and rejoins words hyphenated across line breaks.
+	NormalizationParagraphs Normalization = "paragraphs"
+	// NormalizationCollapsed joins everything into one line with single spaces.
+	NormalizationCollapsed Normalization = "collapsed"
+)
+
+// ParseNormalization validates a normalization mode name. An empty name selects NormalizationCollapsed.
+func ParseNormalization(name string) (Normalization, error) {
+	switch mode := Normalization(name); mode {
+	case "":
+		return NormalizationCollapsed, nil
+	case NormalizationRaw, NormalizationLines, NormalizationParagraphs, NormalizationCollapsed:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown normalization %q", name)
+	}
+}
+
+// Normalize cleans up text according to mode. Lines and paragraphs are also
+// Unicode-normalized (NFKC), which unfolds ligatures and compatibility characters.
+// Unknown modes fall back to NormalizationCollapsed.
+func Normalize(text string, mode Normalization) string {
+	switch mode {
+	case NormalizationRaw:
+		return text
+	case NormalizationLines:
+		return normalizeLines(norm.NFKC.String(text))
+	case NormalizationParagraphs:
+		return normalizeParagraphs(norm.NFKC.String(text))
+	default:
+		return RemoveExtraSpaces(text)
+	}
+}
 
 func RemoveExtraSpaces(text string) string {
 	return strings.Join(strings.Fields(text), " ")
 }
+
+// normalizeLines trims trailing whitespace, keeps at most one blank line in a row and drops leading and trailing blank lines.
+func normalizeLines(text string) string {
+	var out []string
+	blank := false
+	for _, line := range splitLines(text) {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// normalizeParagraphs detects paragraphs and joins the lines of each into a single line.
+// A paragraph ends at a blank line, before an indented line, or after a short line
+// that ends a sentence.
+func normalizeParagraphs(text string) string {
+	lines := splitLines(text)
+
+	// Lines noticeably shorter than the longest one are likely the last line of a paragraph
+	width := 0
+	for _, line := range lines {
+		width = max(width, utf8.RuneCountInString(strings.TrimSpace(line)))
+	}
+	short := width * 3 / 4
+
+	var (
+		paragraphs []string
+		current    string
+	)
+	flush := func() {
+		if current != "" {
+			paragraphs = append(paragraphs, current)
+			current = ""
+		}
+	}
+
+	for i, line := range lines {
+		trimmed := RemoveExtraSpaces(line)
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if current != "" && indent(line) > indent(lines[i-1])+1 {
+			flush()
+		}
+		current = joinWrapped(current, trimmed)
+
+		if utf8.RuneCountInString(trimmed) < short && endsSentence(trimmed) {
+			flush()
+		}
+	}
+	flush()
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// joinWrapped appends next to a paragraph, undoing hyphenation at the line break.
+func joinWrapped(current, next string) string {
+	if current == "" {
+		return next
+	}
+
+	last, _ := utf8.DecodeLastRuneInString(current)
+	first, _ := utf8.DecodeRuneInString(next)
+
+	// "exam-" + "ple" -> "example", but keep "well-" + "Known" and "-" + "5"
+	if last == '-' && unicode.IsLower(first) {
+		beforeHyphen, _ := utf8.DecodeLastRuneInString(current[:len(current)-1])
+		if unicode.IsLetter(beforeHyphen) {
+			return current[:len(current)-1] + next
+		}
+	}
+
+	// Scripts without spaces between words are joined directly
+	if isUnspaced(last) && isUnspaced(first) {
+		return current + next
+	}
+	return current + " " + next
+}
+
+// splitLines splits text on \n, \r\n and \f.
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\f", "\n")
+	return strings.Split(text, "\n")
+}
+
+// indent returns the number of leading whitespace runes of a line.
+func indent(line string) int {
+	return utf8.RuneCountInString(line) - utf8.RuneCountInString(strings.TrimLeftFunc(line, unicode.IsSpace))
+}
+
+// endsSentence reports whether the line ends with sentence punctuation.
+func endsSentence(line string) bool {
+	last, _ := utf8.DecodeLastRuneInString(line)
+	return strings.ContainsRune(".!?:;。！？：；", last)
+}
+
+// isUnspaced reports whether r belongs to a script written without spaces between words.
+func isUnspaced(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai) ||
+		strings.ContainsRune("。，、！？：；「」『』（）", r)
+}
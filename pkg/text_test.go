@@ -0,0 +1,109 @@
+package pkg
+
+import "testing"
+
+func TestParseNormalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Normalization
+		wantErr  bool
+	}{
+		{"", NormalizationCollapsed, false},
+		{"raw", NormalizationRaw, false},
+		{"lines", NormalizationLines, false},
+		{"paragraphs", NormalizationParagraphs, false},
+		{"collapsed", NormalizationCollapsed, false},
+		{"markdown", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseNormalization(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseNormalization(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	layout := "Item        Qty   Price   \n" +
+		"Coffee        2    3.50\n" +
+		"\n\n\n" +
+		"Total             7.00  \n\n"
+
+	tests := []struct {
+		name     string
+		text     string
+		mode     Normalization
+		expected string
+	}{
+		{
+			name:     "raw keeps text untouched",
+			text:     layout,
+			mode:     NormalizationRaw,
+			expected: layout,
+		},
+		{
+			name:     "collapsed matches RemoveExtraSpaces",
+			text:     layout,
+			mode:     NormalizationCollapsed,
+			expected: "Item Qty Price Coffee 2 3.50 Total 7.00",
+		},
+		{
+			name:     "unknown mode collapses",
+			text:     "a \n b",
+			mode:     "other",
+			expected: "a b",
+		},
+		{
+			name:     "lines keeps columns and squeezes blank lines",
+			text:     layout,
+			mode:     NormalizationLines,
+			expected: "Item        Qty   Price\nCoffee        2    3.50\n\nTotal             7.00",
+		},
+		{
+			name:     "lines unfolds ligatures",
+			text:     "ﬁnal oﬀer",
+			mode:     NormalizationLines,
+			expected: "final offer",
+		},
+		{
+			name: "paragraphs join wrapped lines at blank lines",
+			text: "The quick brown fox jumps over\nthe lazy dog and keeps on\nrunning.\n\nSecond paragraph here.",
+			mode: NormalizationParagraphs,
+			expected: "The quick brown fox jumps over the lazy dog and keeps on running.\n\n" +
+				"Second paragraph here.",
+		},
+		{
+			name:     "paragraphs break after short sentence lines",
+			text:     "This line is long enough to set the width.\nDone.\nNext paragraph starts here and runs on.",
+			mode:     NormalizationParagraphs,
+			expected: "This line is long enough to set the width. Done.\n\nNext paragraph starts here and runs on.",
+		},
+		{
+			name:     "paragraphs break before indented lines",
+			text:     "First paragraph line one\ncontinues here\n    Indented start of the next\none",
+			mode:     NormalizationParagraphs,
+			expected: "First paragraph line one continues here\n\nIndented start of the next one",
+		},
+		{
+			name:     "paragraphs de-hyphenate wrapped words",
+			text:     "An exam-\nple of well-\nKnown and -\nnumbers",
+			mode:     NormalizationParagraphs,
+			expected: "An example of well- Known and - numbers",
+		},
+		{
+			name:     "paragraphs join chinese lines without spaces",
+			text:     "这是一个\n测试",
+			mode:     NormalizationParagraphs,
+			expected: "这是一个测试",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text, tt.mode); got != tt.expected {
+				t.Errorf("Normalize() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}